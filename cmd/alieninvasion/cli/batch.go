@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/munna0908/alien-invasion/simulation"
+	"github.com/munna0908/alien-invasion/types"
+)
+
+// batchCommand runs the invasion configured by the flags with consecutive seeds,
+// one summary line per run followed by the averages
+func batchCommand(e *env, args []string) int {
+	fs := e.flagSet("batch", "")
+	opts := newOptions(fs)
+	runs := fs.Int("runs", 10, "Number of runs, the seeds follow the -seed one")
+
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	if err := opts.load(fs); err != nil {
+		e.errorf("loading config err=%s", err.Error())
+
+		return exitCode(err)
+	}
+
+	if err := opts.config.Validate(); err != nil {
+		e.errorf("validating options err=%s", err.Error())
+
+		return ExitUsage
+	}
+
+	if *runs <= 0 {
+		e.errorf("validating options err=runs: must be positive")
+
+		return ExitUsage
+	}
+
+	if opts.config.Seed == 0 {
+		opts.config.Seed = time.Now().UnixNano()
+	}
+
+	total := runSummary{}
+
+	for run := 0; run < *runs; run++ {
+		config := opts.config
+		config.Seed += int64(run)
+
+		invasion, err := prepare(e, &config, simulation.WithOutput(ioutil.Discard))
+		if err != nil {
+			e.errorf("%s", err.Error())
+
+			return exitCode(err)
+		}
+
+		if errors.Is(invasion.simulator.Run(e.closeCh), simulation.ErrStopped) {
+			return ExitCancelled
+		}
+
+		summary := summarize(invasion.simulator)
+		fmt.Fprintf(e.stdout, "seed=%d iterations=%d cities=%d aliens=%d destroyed=%d\n",
+			config.Seed, summary.Iterations, summary.Cities, summary.Aliens, summary.Destroyed)
+
+		total.Iterations += summary.Iterations
+		total.Cities += summary.Cities
+		total.Aliens += summary.Aliens
+		total.Destroyed += summary.Destroyed
+	}
+
+	n := float64(*runs)
+	fmt.Fprintf(e.stdout, "runs=%d average iterations=%.2f cities=%.2f aliens=%.2f destroyed=%.2f\n", *runs,
+		float64(total.Iterations)/n, float64(total.Cities)/n, float64(total.Aliens)/n, float64(total.Destroyed)/n)
+
+	return ExitOK
+}
+
+// runSummary counts the iterations run, the cities and aliens left and the cities destroyed by a run
+type runSummary struct {
+	Iterations int
+	Cities     int
+	Aliens     int
+	Destroyed  int
+}
+
+// summarize sums up the outcome of the run
+func summarize(simulator *simulation.Simulation) runSummary {
+	summary := runSummary{Iterations: simulator.Iterations(), Cities: len(simulator.Cities())}
+
+	for _, alien := range simulator.Aliens() {
+		if alien.Status != types.Dead {
+			summary.Aliens++
+		}
+	}
+
+	for _, event := range simulator.Events() {
+		if event.Kind == simulation.EventCityDestroyed {
+			summary.Destroyed++
+		}
+	}
+
+	return summary
+}
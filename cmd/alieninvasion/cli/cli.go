@@ -4,10 +4,9 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-
-	"github.com/munna0908/alien-invasion/simulation"
+	"strings"
 )
 
 const (
@@ -16,81 +15,123 @@ const (
 	DefaultAliens     = 0
 )
 
-var (
-	maxIterations int
-	alientsCount  int
-	worldFilePath string
+// Exit codes of the command
+const (
+	ExitOK = 0
+	// ExitFailure reports a run which could not complete, a failed verification or a map with issues
+	ExitFailure = 1
+	// ExitUsage reports invalid flags, arguments or options
+	ExitUsage = 2
+	// ExitBadMap reports a map file which cannot be loaded
+	ExitBadMap = 3
+	// ExitCancelled reports a run stopped by a signal
+	ExitCancelled = 130
 )
 
-func init() {
-	flag.IntVar(&maxIterations, "iterations", DefaultIterations, "Number of iterations")
-	flag.IntVar(&alientsCount, "aliens", 0, "Number of aliens")
-	flag.StringVar(&worldFilePath, "input-file", "", "Location of input world file")
-	flag.Parse()
+var ErrBadMap = errors.New("bad map")
+
+// env holds the outputs and the stop signal of a command
+type env struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	closeCh chan os.Signal
+}
+
+// command is a subcommand of the program, run with the arguments following its name
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, args []string) int
 }
 
-func validateFlags() error {
-	if maxIterations <= 0 {
-		return errors.New("invalid iterations")
+// commands returns the subcommands, the first one runs when no subcommand is named
+func commands() []command {
+	return []command{
+		{name: "run", summary: "Simulate the invasion of a map", run: runCommand},
+		{name: "verify", summary: "Check the expectations of a verification file against a seeded run", run: verifyCommand},
+		{name: "lint", summary: "Report the issues of map files", run: lintCommand},
+		{name: "render", summary: "Print a map file in the map format or as a Graphviz graph", run: renderCommand},
+		{name: "generate", summary: "Generate a random grid map", run: generateCommand},
+		{name: "batch", summary: "Run the invasion with consecutive seeds and summarise the runs", run: batchCommand},
+		{name: "replay", summary: "Print the events of a recorded run, or check that the run replays identically", run: replayCommand},
 	}
+}
+
+// Run runs the subcommand named by the first argument, the arguments starting with a
+// flag run the invasion. It returns the exit code of the process.
+func Run(args []string, stdout, stderr io.Writer, closeCh chan os.Signal) int {
+	e := &env{stdout: stdout, stderr: stderr, closeCh: closeCh}
+	cmds := commands()
 
-	if alientsCount <= 0 {
-		return errors.New("invalid aliens count")
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return cmds[0].run(e, args)
 	}
 
-	if len(worldFilePath) == 0 {
-		return errors.New("invalid file path")
+	for _, cmd := range cmds {
+		if cmd.name == args[0] {
+			return cmd.run(e, args[1:])
+		}
 	}
 
-	if _, err := os.Stat(worldFilePath); os.IsNotExist(err) {
-		return errors.New("world file not found")
+	if args[0] != "help" {
+		e.errorf("unknown command %q", args[0])
 	}
 
-	return nil
+	e.usage()
+
+	return ExitUsage
 }
 
-func Execute(closeCh chan os.Signal) {
-	// Validate the flags
-	if err := validateFlags(); err != nil {
-		log.Printf("Error validating flags err=%s \n", err.Error())
-		flag.Usage()
+// usage prints the subcommands
+func (e *env) usage() {
+	fmt.Fprintln(e.stderr, "Usage: alieninvasion [command] [flags] [arguments]")
+	fmt.Fprintln(e.stderr, "Commands:")
 
-		return
+	for _, cmd := range commands() {
+		fmt.Fprintf(e.stderr, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
 
-	// Build the world map
-	worldMap, cities, err := simulation.BuildMap(worldFilePath)
-	if err != nil {
-		log.Printf("Error building world map err=%s \n", err.Error())
+	fmt.Fprintln(e.stderr, "Run \"alieninvasion <command> -h\" for the flags of a command, run is the default command.")
+}
 
-		return
-	}
-	// Assumption: Aliens_count <= 2*Cities_count
-	if alientsCount > 2*len(cities) {
-		log.Printf("Error invalid aliens count")
+// errorf reports an error on the error output
+func (e *env) errorf(format string, args ...interface{}) {
+	fmt.Fprintf(e.stderr, "Error "+format+" \n", args...)
+}
 
-		return
+// flagSet returns the flag set of the command, writing its usage on the error output
+func (e *env) flagSet(name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s:\n  alieninvasion %s [flags] %s\n", name, name, arguments)
+		fs.PrintDefaults()
 	}
-	// Create Simulation instance
-	simulator, err := simulation.NewSimulation(worldMap, alientsCount, maxIterations)
-	if err != nil {
-		log.Printf("Error creating Simulation instance err=%s \n", err.Error())
 
-		return
-	}
-	// Allocate aliens to the cities
-	if err = simulator.InitAliens(cities, alientsCount); err != nil {
-		log.Printf("Error initiating aliens err=%s \n", err.Error())
+	return fs
+}
 
-		return
+// parse parses the arguments of the command, the exit code is returned when the command has to stop
+func (e *env) parse(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK, false
+		}
+
+		return ExitUsage, false
 	}
 
-	fmt.Println("*****************************************")
-	fmt.Println("Aliens Started Invasion ...!!! ")
-	fmt.Println("*****************************************")
+	return ExitOK, true
+}
+
+// exitCode returns the exit code reporting the error
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrBadMap):
+		return ExitBadMap
+	case errors.Is(err, ErrInvalidConfig):
+		return ExitUsage
+	}
 
-	// Start the simulation
-	simulator.Run(closeCh)
-	//Print the left over cities
-	simulation.PrintMap(worldMap)
+	return ExitFailure
 }
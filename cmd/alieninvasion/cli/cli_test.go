@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/simulation"
+	"github.com/stretchr/testify/require"
+)
+
+const testMap = `Bonn north=Koln
+Koln south=Bonn north=Essen
+Essen south=Koln
+`
+
+// writeFile writes the content into a file of the test directory and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	filePath := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0600))
+
+	return filePath
+}
+
+// run runs the command and returns its exit code and outputs
+func run(args ...string) (int, string, string) {
+	return runWithSignal(make(chan os.Signal, 1), args...)
+}
+
+func runWithSignal(closeCh chan os.Signal, args ...string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := Run(args, stdout, stderr, closeCh)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := writeFile(t, dir, "map.txt", testMap)
+	badMap := writeFile(t, dir, "bad.txt", "Bonn north\n")
+
+	testCases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "run", args: []string{"run", "-aliens", "2", "-input-file", mapPath, "-seed", "1"}, code: ExitOK},
+		{name: "default command", args: []string{"-aliens", "2", "-input-file", mapPath, "-seed", "1"}, code: ExitOK},
+		{name: "help", args: []string{"run", "-h"}, code: ExitOK},
+		{name: "no arguments", args: nil, code: ExitUsage},
+		{name: "unknown flag", args: []string{"-invade"}, code: ExitUsage},
+		{name: "invalid option", args: []string{"-aliens", "0", "-input-file", mapPath}, code: ExitUsage},
+		{name: "unknown command", args: []string{"invade"}, code: ExitUsage},
+		{name: "missing map", args: []string{"-aliens", "2", "-input-file", filepath.Join(dir, "none.txt")}, code: ExitBadMap},
+		{name: "bad map", args: []string{"-aliens", "2", "-input-file", badMap}, code: ExitBadMap},
+		{name: "too many aliens", args: []string{"-aliens", "20", "-input-file", mapPath}, code: ExitFailure},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _, _ := run(tc.args...)
+			require.Equal(t, tc.code, code)
+		})
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	mapPath := writeFile(t, t.TempDir(), "map.txt", testMap)
+
+	code, stdout, stderr := run("-aliens", "2", "-input-file", mapPath, "-seed", "1")
+	require.Equal(t, ExitOK, code)
+	require.Empty(t, stderr)
+	require.Contains(t, stdout, "Seed 1")
+	require.Contains(t, stdout, "Cities Left After Invasion")
+	require.Contains(t, stdout, "Alien Histories")
+
+	closeCh := make(chan os.Signal, 1)
+	closeCh <- os.Interrupt
+	code, stdout, _ = runWithSignal(closeCh, "-aliens", "2", "-input-file", mapPath, "-seed", "1")
+	require.Equal(t, ExitCancelled, code)
+	require.Contains(t, stdout, "Stopping the invasion")
+}
+
+func TestRunReportFormats(t *testing.T) {
+	mapPath := writeFile(t, t.TempDir(), "map.txt", testMap)
+	args := []string{"-aliens", "2", "-input-file", mapPath, "-seed", "1", "-format"}
+
+	code, stdout, _ := run(append(args, "json")...)
+	require.Equal(t, ExitOK, code)
+
+	report := simulation.Report{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	require.Equal(t, int64(1), report.Metadata.Seed)
+	require.Equal(t, mapPath, report.Metadata.Map)
+	require.Equal(t, 2, report.Metadata.Aliens)
+	require.Equal(t, 3, report.Metadata.CitiesLeft+len(report.Destroyed))
+
+	code, stdout, _ = run(append(args, "csv")...)
+	require.Equal(t, ExitOK, code)
+	require.True(t, strings.HasPrefix(stdout, "key,value\n"))
+	require.Contains(t, stdout, "\nid,name,faction,class,status,location\n")
+
+	code, stdout, _ = run(append(args, "markdown")...)
+	require.Equal(t, ExitOK, code)
+	require.True(t, strings.HasPrefix(stdout, "# Invasion Report\n"))
+	require.Contains(t, stdout, "## Destroyed Cities")
+
+	code, _, stderr := run(append(args, "xml")...)
+	require.Equal(t, ExitUsage, code)
+	require.Contains(t, stderr, `unknown report format "xml"`)
+}
+
+func TestPrintConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "map.txt", testMap)
+	configPath := writeFile(t, dir, "config.json", `{"input-file": "map.txt", "aliens": 2, "capacity": 3}`)
+
+	code, stdout, _ := run("-config", configPath, "-capacity", "4", "-format", "json", "-print-config")
+	require.Equal(t, ExitOK, code)
+
+	config := Config{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &config))
+	require.Equal(t, filepath.Join(dir, "map.txt"), config.InputFile)
+	require.Equal(t, 2, config.Aliens)
+	require.Equal(t, 4, config.Capacity)
+	require.Equal(t, DefaultIterations, config.Iterations)
+	require.Equal(t, "json", config.Format)
+
+	badConfig := writeFile(t, dir, "bad.json", `{"alienz": 2}`)
+	code, _, stderr := run("-config", badConfig)
+	require.Equal(t, ExitUsage, code)
+	require.Contains(t, stderr, "alienz")
+}
+
+func TestVerifyCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "map.txt", testMap)
+	settings := "@input-file map.txt\n@aliens 2\n@iterations 10\n@seed 1\n"
+	passing := writeFile(t, dir, "pass.verify", settings+"at most 3 cities destroyed\n")
+	failing := writeFile(t, dir, "fail.verify", settings+"at least 4 cities destroyed\n")
+	unseeded := writeFile(t, dir, "unseeded.verify", "@input-file map.txt\n@aliens 2\n")
+
+	code, stdout, _ := run("verify", passing)
+	require.Equal(t, ExitOK, code)
+
+	report := VerifyReport{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	require.True(t, report.Passed)
+	require.Equal(t, int64(1), report.Seed)
+
+	code, stdout, _ = run("verify", failing)
+	require.Equal(t, ExitFailure, code)
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	require.False(t, report.Passed)
+	require.Equal(t, "at least 4", report.Results[0].Expected)
+
+	code, _, _ = run("verify", unseeded)
+	require.Equal(t, ExitUsage, code)
+
+	code, _, _ = run("verify")
+	require.Equal(t, ExitUsage, code)
+}
+
+func TestMapCommands(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := writeFile(t, dir, "map.txt", testMap)
+	asymmetric := writeFile(t, dir, "asymmetric.txt", "Bonn north=Koln\nEssen south=Dortmund\n")
+
+	code, stdout, _ := run("lint", mapPath)
+	require.Equal(t, ExitOK, code)
+	require.Equal(t, mapPath+": ok\n", stdout)
+
+	code, stdout, _ = run("lint", asymmetric)
+	require.Equal(t, ExitFailure, code)
+	require.Contains(t, stdout, "asymmetric road Bonn north=Koln has no road back")
+	require.Contains(t, stdout, "disconnected region of 2 cities: Dortmund, Essen")
+
+	code, stdout, _ = run("render", mapPath)
+	require.Equal(t, ExitOK, code)
+	require.Equal(t, "Bonn north=Koln \nEssen south=Koln \nKoln north=Essen south=Bonn \n", stdout)
+
+	code, stdout, _ = run("render", "-format", "dot", mapPath)
+	require.Equal(t, ExitOK, code)
+	require.Contains(t, stdout, `"Koln" -> "Essen" [label="north", weight=1, length=1];`)
+
+	code, stdout, _ = run("generate", "-cities", "9", "-seed", "4")
+	require.Equal(t, ExitOK, code)
+
+	generated := writeFile(t, dir, "generated.txt", stdout)
+	code, _, _ = run("lint", generated)
+	require.Equal(t, ExitOK, code)
+
+	code, _, _ = run("generate", "-cities", "1")
+	require.Equal(t, ExitUsage, code)
+	// The maps of the other direction sets keep their @directions header
+	eightWay := writeFile(t, dir, "8way.txt", "@directions 8way\nA northeast=B\nB southwest=A\n")
+	code, stdout, _ = run("render", eightWay)
+	require.Equal(t, ExitOK, code)
+	require.Equal(t, "@directions 8way\nA northeast=B \nB southwest=A \n", stdout)
+
+	rendered := writeFile(t, dir, "rendered.txt", stdout)
+	code, _, _ = run("lint", rendered)
+	require.Equal(t, ExitOK, code)
+
+	code, stdout, _ = run("generate", "-cities", "12", "-directions", "hex", "-seed", "4")
+	require.Equal(t, ExitOK, code)
+	require.Contains(t, stdout, "@directions hex\n")
+
+	code, _, _ = run("lint", writeFile(t, dir, "hex.txt", stdout))
+	require.Equal(t, ExitOK, code)
+
+	code, _, _ = run("generate", "-directions", "12way")
+	require.Equal(t, ExitUsage, code)
+}
+
+func TestBatchCommand(t *testing.T) {
+	mapPath := writeFile(t, t.TempDir(), "map.txt", testMap)
+
+	code, stdout, _ := run("batch", "-runs", "3", "-aliens", "2", "-input-file", mapPath, "-seed", "7", "-iterations", "10")
+	require.Equal(t, ExitOK, code)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	require.True(t, strings.HasPrefix(lines[0], "seed=7 "))
+	require.True(t, strings.HasPrefix(lines[2], "seed=9 "))
+	require.True(t, strings.HasPrefix(lines[3], "runs=3 average"))
+}
+
+func TestReplayCommand(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := writeFile(t, dir, "map.txt", testMap)
+	recordPath := filepath.Join(dir, "run.json")
+
+	code, _, _ := run("-aliens", "2", "-input-file", mapPath, "-seed", "3", "-record", recordPath)
+	require.Equal(t, ExitOK, code)
+
+	code, stdout, _ := run("replay", "-check", recordPath)
+	require.Equal(t, ExitOK, code)
+	require.Contains(t, stdout, "replay matched")
+
+	recording, err := readRecording(recordPath)
+	require.NoError(t, err)
+	require.NotEmpty(t, recording.Events)
+
+	code, stdout, _ = run("replay", recordPath)
+	require.Equal(t, ExitOK, code)
+	require.Equal(t, recording.Events[0].String()+"\n", strings.SplitAfterN(stdout, "\n", 2)[0])
+
+	// A tampered recording no longer replays identically
+	recording.Events = recording.Events[1:]
+	require.NoError(t, writeRecording(recordPath, recording))
+
+	code, stdout, _ = run("replay", "-check", recordPath)
+	require.Equal(t, ExitFailure, code)
+	require.Contains(t, stdout, "replay diverged at event 0")
+}
@@ -0,0 +1,387 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/simulation"
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Config holds every option of a run, the JSON keys are the names of the command line flags
+type Config struct {
+	InputFile     string `json:"input-file"`
+	Directions    string `json:"directions"`
+	CheckSymmetry bool   `json:"check-symmetry"`
+	Aliens        int    `json:"aliens"`
+	Iterations    int    `json:"iterations"`
+	Seed          int64  `json:"seed"`
+	Capacity      int    `json:"capacity"`
+	Placement     string `json:"placement"`
+	Clusters      int    `json:"clusters"`
+	PlacementFile string `json:"placement-file"`
+	AlienNames    string `json:"alien-names"`
+	Factions      string `json:"factions"`
+	Classes       string `json:"classes"`
+	// Battle outcomes
+	VictoryChance       float64 `json:"victory-chance"`
+	DamageChance        float64 `json:"damage-chance"`
+	StalemateChance     float64 `json:"stalemate-chance"`
+	DefenseKillChance   float64 `json:"defense-kill-chance"`
+	DefenseAbsorbChance float64 `json:"defense-absorb-chance"`
+	// Roads
+	RoadFights            bool    `json:"road-fights"`
+	RoadEncounter         string  `json:"road-encounter"`
+	RoadDestroyChance     float64 `json:"road-destroy-chance"`
+	Simultaneous          bool    `json:"simultaneous"`
+	TraverseDestroyChance float64 `json:"traverse-destroy-chance"`
+	TraverseBlockChance   float64 `json:"traverse-block-chance"`
+	// Cities and reinforcements
+	Recovery          int     `json:"recovery"`
+	Spawns            string  `json:"spawns"`
+	SpawnFile         string  `json:"spawn-file"`
+	Scenario          string  `json:"scenario"`
+	Motherships       string  `json:"motherships"`
+	LaunchInterval    int     `json:"launch-interval"`
+	MothershipAssault int     `json:"mothership-assault"`
+	HumanStrikeChance float64 `json:"human-strike-chance"`
+	Policies          string  `json:"policies"`
+	// Energy
+	Energy           int     `json:"energy"`
+	MoveCost         int     `json:"move-cost"`
+	WeightedMoveCost bool    `json:"weighted-move-cost"`
+	RestGain         int     `json:"rest-gain"`
+	RestChance       float64 `json:"rest-chance"`
+	// Outputs, only used by the run command
+	Format string `json:"format"`
+	Record string `json:"record"`
+}
+
+// DefaultConfig returns the settings used for the options missing from the flags and the config file
+func DefaultConfig() Config {
+	return Config{
+		Directions:          types.FourWay.Name,
+		Iterations:          DefaultIterations,
+		Aliens:              DefaultAliens,
+		Capacity:            simulation.DefaultCityCapacity,
+		Placement:           simulation.PlacementUniform,
+		Clusters:            1,
+		DefenseKillChance:   simulation.DefaultDefenseModel.Kill,
+		DefenseAbsorbChance: simulation.DefaultDefenseModel.Absorb,
+		LaunchInterval:      simulation.DefaultLaunchInterval,
+		MothershipAssault:   simulation.DefaultAssault,
+		MoveCost:            1,
+		RestGain:            1,
+		Format:              simulation.FormatText,
+	}
+}
+
+// registerFlags binds the flags of the options to the config fields, their defaults are the current values
+func (c *Config) registerFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.Iterations, "iterations", c.Iterations, "Number of iterations")
+	fs.IntVar(&c.Aliens, "aliens", c.Aliens, "Number of aliens")
+	fs.StringVar(&c.InputFile, "input-file", "", "Location of input world file")
+	fs.IntVar(&c.Capacity, "capacity", c.Capacity, "Maximum number of aliens allocated to a city")
+	fs.StringVar(&c.Placement, "placement", c.Placement,
+		"Alien placement strategy (uniform, clustered, degree, edge, attribute:<key>)")
+	fs.IntVar(&c.Clusters, "clusters", c.Clusters, "Number of seed cities used by the clustered placement")
+	fs.StringVar(&c.AlienNames, "alien-names", "", "Location of the file with one alien name per line")
+	fs.StringVar(&c.Factions, "factions", "",
+		"Alien factions, either names assigned round-robin (red,blue) or counts (red:3,blue:2)")
+	fs.StringVar(&c.PlacementFile, "placement-file", "", "Location of the file with one \"<city> [faction]\" entry per alien")
+	fs.Int64Var(&c.Seed, "seed", 0, "Seed of the random source, a time based seed is used when zero")
+	fs.Float64Var(&c.VictoryChance, "victory-chance", 0, "Probability that a single alien wins a battle and the city survives")
+	fs.Float64Var(&c.DamageChance, "damage-chance", 0,
+		"Probability that every alien dies in a battle and the city is damaged, a damaged city is destroyed by the next damage")
+	fs.Float64Var(&c.StalemateChance, "stalemate-chance", 0,
+		"Probability that every alien dies in a battle and the city survives untouched")
+	fs.Float64Var(&c.DefenseKillChance, "defense-kill-chance", c.DefenseKillChance,
+		"Probability, per defense level, that a city kills an arriving alien")
+	fs.Float64Var(&c.DefenseAbsorbChance, "defense-absorb-chance", c.DefenseAbsorbChance,
+		"Probability, per defense level, that a city absorbs a battle without being destroyed")
+	fs.StringVar(&c.Directions, "directions", c.Directions,
+		"Direction set of maps without a @directions header (4way, 8way, hex, 3d)")
+	fs.BoolVar(&c.CheckSymmetry, "check-symmetry", false, "Report the roads without a road back in the opposite direction")
+	fs.BoolVar(&c.RoadFights, "road-fights", false, "Make the aliens meeting head-on on a multi-tick road fight there")
+	fs.StringVar(&c.RoadEncounter, "road-encounter", "",
+		"Outcome of rival aliens crossing each other on a road (none, fight, duel), overrides -road-fights")
+	fs.Float64Var(&c.RoadDestroyChance, "road-destroy-chance", 0, "Probability that a road encounter destroys the road")
+	fs.Float64Var(&c.TraverseDestroyChance, "traverse-destroy-chance", 0,
+		"Probability that an alien destroys the road it takes, both ways")
+	fs.Float64Var(&c.TraverseBlockChance, "traverse-block-chance", 0,
+		"Probability that an alien blocks the road it takes, both ways")
+	fs.IntVar(&c.Recovery, "recovery", 0,
+		"Number of iterations after which a destroyed city is rebuilt from its ruins, zero keeps it destroyed")
+	fs.StringVar(&c.Spawns, "spawns", "",
+		"Reinforcements landing during the invasion, e.g. \"every 100: spawn 5; at 500: spawn 10 at Berlin\"")
+	fs.StringVar(&c.SpawnFile, "spawn-file", "", "Location of the file with one spawn rule per line")
+	fs.StringVar(&c.Motherships, "motherships", "",
+		"Alien bases launching aliens, in addition to the @mothership cities of the map, e.g. Berlin:red,Paris")
+	fs.IntVar(&c.LaunchInterval, "launch-interval", c.LaunchInterval,
+		"Number of iterations between two launches of a mothership")
+	fs.IntVar(&c.MothershipAssault, "mothership-assault", c.MothershipAssault,
+		"Number of rival alien assaults destroying a mothership")
+	fs.Float64Var(&c.HumanStrikeChance, "human-strike-chance", 0,
+		"Probability that humans destroy a mothership at each iteration")
+	fs.IntVar(&c.Energy, "energy", 0, "Energy of the aliens, an alien dies of exhaustion when it runs out, zero disables it")
+	fs.IntVar(&c.MoveCost, "move-cost", c.MoveCost, "Energy spent by an alien on every move")
+	fs.BoolVar(&c.WeightedMoveCost, "weighted-move-cost", false, "Multiply the cost of a move by the weight of the road")
+	fs.IntVar(&c.RestGain, "rest-gain", c.RestGain, "Energy regained by an alien not moving in an iteration")
+	fs.Float64Var(&c.RestChance, "rest-chance", 0, "Probability that an alien rests instead of moving")
+	fs.StringVar(&c.Classes, "classes", "",
+		"Relative weights of the alien classes (regular, scout, tank, bomber, stealth), e.g. regular:7,scout:2,tank")
+	fs.StringVar(&c.Policies, "policies", "",
+		"Human policies applied in order at every iteration (quarantine, evacuate[:rate], sacrifice[:aliens])")
+	fs.StringVar(&c.Scenario, "scenario", "", "Location of the file with one scripted scenario rule per line")
+	fs.BoolVar(&c.Simultaneous, "simultaneous", false,
+		"Make every alien choose its move before any of them moves, aliens swapping cities meet on the road")
+}
+
+// LoadConfig reads a JSON config file over the defaults, unknown keys are rejected
+// and the relative paths are resolved against the directory of the file
+func LoadConfig(filePath string) (Config, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Config{}, fmt.Errorf("error reading file: %w", err)
+	}
+	defer file.Close()
+
+	config := DefaultConfig()
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("%w: %s: %s", ErrInvalidConfig, filePath, err.Error())
+	}
+
+	for _, path := range []*string{&config.InputFile, &config.PlacementFile, &config.AlienNames,
+		&config.SpawnFile, &config.Scenario, &config.Record} {
+		if len(*path) > 0 && !filepath.IsAbs(*path) {
+			*path = filepath.Join(filepath.Dir(filePath), *path)
+		}
+	}
+
+	return config, nil
+}
+
+// Print writes the config as indented JSON
+func (c Config) Print(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(c)
+}
+
+// Validate checks every option, the error names the offending one
+func (c Config) Validate() error {
+	directions, err := types.GetDirectionSet(c.Directions)
+	checks := []struct {
+		field string
+		err   error
+	}{
+		{"input-file", checkRequired(c.InputFile)},
+		{"iterations", checkPositive(c.Iterations)},
+		{"aliens", checkPositive(c.Aliens)},
+		{"capacity", checkPositive(c.Capacity)},
+		{"clusters", checkPositive(c.Clusters)},
+		{"placement-file", checkFile(c.PlacementFile)},
+		{"alien-names", checkFile(c.AlienNames)},
+		{"spawn-file", checkFile(c.SpawnFile)},
+		{"scenario", checkFile(c.Scenario)},
+		{"directions", err},
+		{"placement", discard(simulation.ParsePlacement(c.Placement, c.Clusters, directions))},
+		{"factions", c.checkFactions()},
+		{"classes", discard(simulation.ParseClassMix(c.Classes))},
+		{"victory-chance", checkChance(c.VictoryChance)},
+		{"damage-chance", checkChance(c.DamageChance)},
+		{"stalemate-chance", checkChance(c.StalemateChance)},
+		{"stalemate-chance", checkTotal([]string{"victory-chance", "damage-chance", "stalemate-chance"},
+			c.VictoryChance, c.DamageChance, c.StalemateChance)},
+		{"defense-kill-chance", checkChance(c.DefenseKillChance)},
+		{"defense-absorb-chance", checkChance(c.DefenseAbsorbChance)},
+		{"road-encounter", discard(c.encounter())},
+		{"road-destroy-chance", checkChance(c.RoadDestroyChance)},
+		{"traverse-destroy-chance", checkChance(c.TraverseDestroyChance)},
+		{"traverse-block-chance", checkChance(c.TraverseBlockChance)},
+		{"traverse-block-chance", checkTotal([]string{"traverse-destroy-chance", "traverse-block-chance"},
+			c.TraverseDestroyChance, c.TraverseBlockChance)},
+		{"recovery", checkNotNegative(c.Recovery)},
+		{"spawns", discard(simulation.ParseSpawnSpec(c.Spawns))},
+		{"motherships", discard(simulation.ParseMotherships(c.Motherships))},
+		{"launch-interval", checkPositive(c.LaunchInterval)},
+		{"mothership-assault", checkPositive(c.MothershipAssault)},
+		{"human-strike-chance", checkChance(c.HumanStrikeChance)},
+		{"policies", discard(simulation.ParsePolicies(c.Policies))},
+		{"energy", checkNotNegative(c.Energy)},
+		{"move-cost", checkNotNegative(c.MoveCost)},
+		{"rest-gain", checkNotNegative(c.RestGain)},
+		{"rest-chance", checkChance(c.RestChance)},
+		{"format", checkFormat(c.Format)},
+	}
+
+	for _, check := range checks {
+		if check.err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrInvalidConfig, check.field, check.err.Error())
+		}
+	}
+
+	return nil
+}
+
+// checkFactions checks the factions spec against the number of aliens
+func (c Config) checkFactions() error {
+	if len(c.Factions) == 0 {
+		return nil
+	}
+
+	_, err := simulation.ParseFactions(c.Factions, c.Aliens)
+
+	return err
+}
+
+func (c Config) battle() simulation.BattleModel {
+	return simulation.BattleModel{Victory: c.VictoryChance, Damaged: c.DamageChance, Stalemate: c.StalemateChance}
+}
+
+func (c Config) defense() simulation.DefenseModel {
+	return simulation.DefenseModel{Kill: c.DefenseKillChance, Absorb: c.DefenseAbsorbChance}
+}
+
+// encounter resolves the road encounter rule, -road-encounter overrides -road-fights
+func (c Config) encounter() (simulation.RoadEncounter, error) {
+	rule := simulation.RoadEncounter{DestroyRoad: c.RoadDestroyChance}
+	if c.RoadFights {
+		rule.Outcome = simulation.EncounterFight
+	}
+
+	if len(c.RoadEncounter) > 0 {
+		outcome, err := simulation.ParseEncounterOutcome(c.RoadEncounter)
+		if err != nil {
+			return simulation.RoadEncounter{}, err
+		}
+
+		rule.Outcome = outcome
+	}
+
+	return rule, nil
+}
+
+func (c Config) roadDamage() simulation.RoadDamage {
+	return simulation.RoadDamage{Destroy: c.TraverseDestroyChance, Block: c.TraverseBlockChance}
+}
+
+func (c Config) mothershipRule() simulation.MothershipRule {
+	return simulation.MothershipRule{Interval: c.LaunchInterval, Assault: c.MothershipAssault, Strike: c.HumanStrikeChance}
+}
+
+func (c Config) energy() simulation.EnergyModel {
+	return simulation.EnergyModel{Max: c.Energy, MoveCost: c.MoveCost, Rest: c.RestGain,
+		RestChance: c.RestChance, Weighted: c.WeightedMoveCost}
+}
+
+// mergeConfig replaces the options with the ones of the config file, except the
+// ones set on the command line, which override the file
+func mergeConfig(fs *flag.FlagSet, config *Config, filePath string) error {
+	loaded, err := LoadConfig(filePath)
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		overrides[f.Name] = f.Value.String()
+	})
+
+	*config = loaded
+
+	for name, value := range overrides {
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrInvalidConfig, name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func checkPositive(value int) error {
+	if value <= 0 {
+		return errors.New("must be positive")
+	}
+
+	return nil
+}
+
+func checkNotNegative(value int) error {
+	if value < 0 {
+		return errors.New("must not be negative")
+	}
+
+	return nil
+}
+
+func checkChance(value float64) error {
+	if value < 0 || value > 1 {
+		return errors.New("must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// checkTotal checks that the chances drawn together do not add up to more than 1
+func checkTotal(fields []string, values ...float64) error {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+
+	if total > 1 {
+		return fmt.Errorf("%s must not add up to more than 1", strings.Join(fields, ", "))
+	}
+
+	return nil
+}
+
+func checkRequired(value string) error {
+	if len(value) == 0 {
+		return errors.New("missing value")
+	}
+
+	return nil
+}
+
+// checkFile checks that the optional file exists
+func checkFile(filePath string) error {
+	if len(filePath) == 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(filePath); err != nil {
+		return errors.New("file not found")
+	}
+
+	return nil
+}
+
+// checkFormat checks that the report format is known
+func checkFormat(format string) error {
+	for _, known := range simulation.Formats {
+		if format == known {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w %q", simulation.ErrUnknownFormat, format)
+}
+
+// discard keeps the error of a parse
+func discard(_ interface{}, err error) error {
+	return err
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	absolute := filepath.Join(t.TempDir(), "map.txt")
+
+	testCases := []struct {
+		name     string
+		data     string
+		expected func(config *Config)
+		err      string
+	}{
+		{
+			name: "defaults for the missing options",
+			data: `{"aliens": 5}`,
+			expected: func(config *Config) {
+				config.Aliens = 5
+			},
+		},
+		{
+			name: "relative paths resolved against the file",
+			data: `{"input-file": "map.txt", "scenario": "rules/what-if.txt", "record": "out/run.json"}`,
+			expected: func(config *Config) {
+				config.InputFile = filepath.Join(dir, "map.txt")
+				config.Scenario = filepath.Join(dir, "rules", "what-if.txt")
+				config.Record = filepath.Join(dir, "out", "run.json")
+			},
+		},
+		{
+			name: "absolute paths kept",
+			data: `{"input-file": "` + absolute + `"}`,
+			expected: func(config *Config) {
+				config.InputFile = absolute
+			},
+		},
+		{
+			name: "output settings",
+			data: `{"format": "csv"}`,
+			expected: func(config *Config) {
+				config.Format = "csv"
+			},
+		},
+		{name: "unknown key", data: `{"alienz": 2}`, err: `unknown field "alienz"`},
+		{name: "wrong type", data: `{"aliens": "two"}`, err: "invalid config"},
+		{name: "not json", data: `aliens=2`, err: "invalid config"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := LoadConfig(writeFile(t, dir, "config.json", tc.data))
+			if tc.err != "" {
+				require.ErrorIs(t, err, ErrInvalidConfig)
+				require.Contains(t, err.Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			expected := DefaultConfig()
+			tc.expected(&expected)
+			require.Equal(t, expected, config)
+		})
+	}
+
+	_, err := LoadConfig(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestMergeConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeFile(t, dir, "config.json", `{"aliens": 3, "capacity": 5, "placement": "degree"}`)
+
+	testCases := []struct {
+		name      string
+		args      []string
+		aliens    int
+		capacity  int
+		placement string
+	}{
+		{name: "file only", args: nil, aliens: 3, capacity: 5, placement: "degree"},
+		{name: "flag overrides the file", args: []string{"-capacity", "4"}, aliens: 3, capacity: 4, placement: "degree"},
+		{name: "flag set to its default overrides the file", args: []string{"-placement", "uniform"},
+			aliens: 3, capacity: 5, placement: "uniform"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+
+			config := DefaultConfig()
+			config.registerFlags(fs)
+			require.NoError(t, fs.Parse(tc.args))
+			require.NoError(t, mergeConfig(fs, &config, configPath))
+
+			require.Equal(t, tc.aliens, config.Aliens)
+			require.Equal(t, tc.capacity, config.Capacity)
+			require.Equal(t, tc.placement, config.Placement)
+			require.Equal(t, DefaultIterations, config.Iterations)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	mapPath := writeFile(t, t.TempDir(), "map.txt", testMap)
+
+	testCases := []struct {
+		name   string
+		update func(config *Config)
+		field  string
+	}{
+		{name: "valid", update: func(config *Config) {}},
+		{name: "missing map", update: func(config *Config) { config.InputFile = "" }, field: "input-file"},
+		{name: "no aliens", update: func(config *Config) { config.Aliens = 0 }, field: "aliens"},
+		{name: "no capacity", update: func(config *Config) { config.Capacity = -1 }, field: "capacity"},
+		{name: "missing scenario", update: func(config *Config) { config.Scenario = "none.txt" }, field: "scenario"},
+		{name: "unknown directions", update: func(config *Config) { config.Directions = "12way" }, field: "directions"},
+		{name: "unknown placement", update: func(config *Config) { config.Placement = "random" }, field: "placement"},
+		{name: "edge placement with 8way directions", update: func(config *Config) {
+			config.Directions, config.Placement = "8way", "edge"
+		}},
+		{name: "victory chance out of range", update: func(config *Config) { config.VictoryChance = 2 }, field: "victory-chance"},
+		{name: "damage chance out of range", update: func(config *Config) { config.DamageChance = 2 }, field: "damage-chance"},
+		{name: "stalemate chance out of range", update: func(config *Config) { config.StalemateChance = -1 },
+			field: "stalemate-chance"},
+		{name: "battle chances over 1", update: func(config *Config) {
+			config.VictoryChance, config.DamageChance = 0.6, 0.6
+		}, field: "stalemate-chance"},
+		{name: "defense kill chance out of range", update: func(config *Config) { config.DefenseKillChance = 2 },
+			field: "defense-kill-chance"},
+		{name: "defense absorb chance out of range", update: func(config *Config) { config.DefenseAbsorbChance = 2 },
+			field: "defense-absorb-chance"},
+		{name: "unknown road encounter", update: func(config *Config) { config.RoadEncounter = "hug" },
+			field: "road-encounter"},
+		{name: "road destroy chance out of range", update: func(config *Config) { config.RoadDestroyChance = 2 },
+			field: "road-destroy-chance"},
+		{name: "traverse destroy chance out of range", update: func(config *Config) { config.TraverseDestroyChance = -1 },
+			field: "traverse-destroy-chance"},
+		{name: "traverse block chance out of range", update: func(config *Config) { config.TraverseBlockChance = 2 },
+			field: "traverse-block-chance"},
+		{name: "no launch interval", update: func(config *Config) { config.LaunchInterval = 0 }, field: "launch-interval"},
+		{name: "no mothership assault", update: func(config *Config) { config.MothershipAssault = 0 },
+			field: "mothership-assault"},
+		{name: "human strike chance out of range", update: func(config *Config) { config.HumanStrikeChance = 2 },
+			field: "human-strike-chance"},
+		{name: "negative energy", update: func(config *Config) { config.Energy = -1 }, field: "energy"},
+		{name: "negative move cost", update: func(config *Config) { config.MoveCost = -1 }, field: "move-cost"},
+		{name: "negative rest gain", update: func(config *Config) { config.RestGain = -1 }, field: "rest-gain"},
+		{name: "rest chance out of range", update: func(config *Config) { config.RestChance = 2 }, field: "rest-chance"},
+		{name: "negative recovery", update: func(config *Config) { config.Recovery = -1 }, field: "recovery"},
+		{name: "unknown format", update: func(config *Config) { config.Format = "xml" }, field: "format"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.InputFile, config.Aliens = mapPath, 2
+			tc.update(&config)
+
+			err := config.Validate()
+			if tc.field == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidConfig)
+			require.Contains(t, err.Error(), "invalid config: "+tc.field+": ")
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/munna0908/alien-invasion/simulation"
+	"github.com/munna0908/alien-invasion/types"
+)
+
+const (
+	formatMap = "map"
+	formatDOT = "dot"
+)
+
+// lintCommand reports the asymmetric roads and the disconnected regions of the map files
+func lintCommand(e *env, args []string) int {
+	fs := e.flagSet("lint", "<map file>...")
+	directions := fs.String("directions", types.FourWay.Name,
+		"Direction set of maps without a @directions header (4way, 8way, hex, 3d)")
+
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return ExitUsage
+	}
+
+	code := ExitOK
+
+	for _, filePath := range fs.Args() {
+		loadedMap, err := loadMap(filePath, *directions)
+		if err != nil {
+			e.errorf("%s", err.Error())
+
+			return exitCode(err)
+		}
+
+		issues := make([]string, 0)
+		for _, issue := range simulation.CheckSymmetry(loadedMap.World, loadedMap.Directions) {
+			issues = append(issues, fmt.Sprintf("asymmetric road %s", issue))
+		}
+
+		if regions := simulation.Regions(loadedMap.World); len(regions) > 1 {
+			for _, region := range regions {
+				issues = append(issues, fmt.Sprintf("disconnected region of %d cities: %s", len(region), strings.Join(region, ", ")))
+			}
+		}
+
+		for _, issue := range issues {
+			fmt.Fprintf(e.stdout, "%s: %s\n", filePath, issue)
+		}
+
+		if len(issues) > 0 {
+			code = ExitFailure
+		} else {
+			fmt.Fprintf(e.stdout, "%s: ok\n", filePath)
+		}
+	}
+
+	return code
+}
+
+// renderCommand prints the map file in the map format or as a Graphviz graph
+func renderCommand(e *env, args []string) int {
+	fs := e.flagSet("render", "<map file>")
+	directions := fs.String("directions", types.FourWay.Name,
+		"Direction set of maps without a @directions header (4way, 8way, hex, 3d)")
+	format := fs.String("format", formatMap, "Output format (map, dot)")
+
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 || (*format != formatMap && *format != formatDOT) {
+		fs.Usage()
+
+		return ExitUsage
+	}
+
+	loadedMap, err := loadMap(fs.Arg(0), *directions)
+	if err != nil {
+		e.errorf("%s", err.Error())
+
+		return exitCode(err)
+	}
+
+	if *format == formatDOT {
+		simulation.PrintDOT(e.stdout, loadedMap.World)
+	} else {
+		simulation.PrintWorld(e.stdout, loadedMap.World, loadedMap.Directions)
+	}
+
+	return ExitOK
+}
+
+// generateCommand prints a random grid map in the map format
+func generateCommand(e *env, args []string) int {
+	fs := e.flagSet("generate", "")
+	cities := fs.Int("cities", 16, "Number of cities")
+	roadChance := fs.Float64("road-chance", 1, "Probability that two adjacent cities are linked by a road")
+	directions := fs.String("directions", types.FourWay.Name, "Direction set of the grid (4way, 8way, hex, 3d)")
+	seed := fs.Int64("seed", 0, "Seed of the random source, a time based seed is used when zero")
+
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	directionSet, err := types.GetDirectionSet(*directions)
+	if err != nil {
+		e.errorf("generating map err=%s", err.Error())
+
+		return ExitUsage
+	}
+
+	world, err := simulation.GenerateGrid(*cities, *roadChance, directionSet, rand.New(rand.NewSource(*seed))) //nolint:gosec
+	if err != nil {
+		e.errorf("generating map err=%s", err.Error())
+
+		return ExitUsage
+	}
+
+	fmt.Fprintf(e.stdout, "# generated with -cities %d -road-chance %g -directions %s -seed %d\n",
+		*cities, *roadChance, directionSet.Name, *seed)
+	simulation.PrintWorld(e.stdout, world, directionSet)
+
+	return ExitOK
+}
+
+// loadMap loads the map file with the given default direction set
+func loadMap(filePath, directions string) (*simulation.Map, error) {
+	directionSet, err := types.GetDirectionSet(directions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid directions: %s", ErrInvalidConfig, err.Error())
+	}
+
+	loadedMap, err := simulation.LoadMap(filePath, directionSet)
+	if err != nil {
+		return nil, fmt.Errorf("%w: building world map: %s", ErrBadMap, err.Error())
+	}
+
+	return loadedMap, nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/munna0908/alien-invasion/simulation"
+)
+
+// replayCommand prints the events of a run recorded with run -record, or re-runs
+// its config and checks that the same events happen
+func replayCommand(e *env, args []string) int {
+	fs := e.flagSet("replay", "<recording>")
+	until := fs.Int("until", -1, "Last iteration replayed, every iteration when negative")
+	check := fs.Bool("check", false, "Re-run the recorded config and check that the run replays identically")
+
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return ExitUsage
+	}
+
+	recording, err := readRecording(fs.Arg(0))
+	if err != nil {
+		e.errorf("reading recording err=%s", err.Error())
+
+		return ExitUsage
+	}
+
+	if *check {
+		return checkReplay(e, recording)
+	}
+
+	for _, event := range recording.Events {
+		if *until >= 0 && event.Iteration > *until {
+			break
+		}
+
+		fmt.Fprintln(e.stdout, event.String())
+	}
+
+	return ExitOK
+}
+
+// checkReplay re-runs the recorded config, the first diverging event is reported
+func checkReplay(e *env, recording Recording) int {
+	config := recording.Config
+
+	invasion, err := prepare(e, &config, simulation.WithOutput(ioutil.Discard))
+	if err != nil {
+		e.errorf("%s", err.Error())
+
+		return exitCode(err)
+	}
+
+	if errors.Is(invasion.simulator.Run(e.closeCh), simulation.ErrStopped) {
+		return ExitCancelled
+	}
+
+	events := invasion.simulator.Events()
+
+	for i := 0; i < len(events) || i < len(recording.Events); i++ {
+		if i >= len(events) || i >= len(recording.Events) || !sameEvent(events[i], recording.Events[i]) {
+			fmt.Fprintf(e.stdout, "replay diverged at event %d\n  recorded: %s\n  replayed: %s\n",
+				i, eventAt(recording.Events, i), eventAt(events, i))
+
+			return ExitFailure
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "replay matched %d events\n", len(events))
+
+	return ExitOK
+}
+
+// sameEvent compares the events as recorded, i.e. in JSON
+func sameEvent(a, b simulation.Event) bool {
+	first, _ := json.Marshal(a)
+	second, _ := json.Marshal(b)
+
+	return bytes.Equal(first, second)
+}
+
+// eventAt describes the i-th event, if any
+func eventAt(events []simulation.Event, i int) string {
+	if i >= len(events) {
+		return "none"
+	}
+
+	return fmt.Sprintf("iteration %d: %s", events[i].Iteration, events[i].String())
+}
+
+// readRecording reads a recording written by run -record
+func readRecording(filePath string) (Recording, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Recording{}, fmt.Errorf("error reading file: %w", err)
+	}
+
+	recording := Recording{Config: DefaultConfig()}
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return Recording{}, err
+	}
+
+	return recording, nil
+}
@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/munna0908/alien-invasion/simulation"
+	"github.com/munna0908/alien-invasion/types"
+)
+
+// options are the run options given by the flags and the config file
+type options struct {
+	config     Config
+	configPath string
+}
+
+// invasion is a simulation ready to run, along with what its reports need
+type invasion struct {
+	simulator    *simulation.Simulation
+	world        types.World
+	withFactions bool
+	policies     []simulation.Policy
+}
+
+// Recording is the config and the events of a run, written by run -record and read by replay
+type Recording struct {
+	Config Config             `json:"config"`
+	Events []simulation.Event `json:"events"`
+}
+
+// newOptions registers the run options on the flag set
+func newOptions(fs *flag.FlagSet) *options {
+	o := &options{config: DefaultConfig()}
+	o.config.registerFlags(fs)
+	fs.StringVar(&o.configPath, "config", "", "Location of the JSON config file, the flags override its options")
+
+	return o
+}
+
+// load merges the config file, if any, under the flags set on the command line
+func (o *options) load(fs *flag.FlagSet) error {
+	if len(o.configPath) == 0 {
+		return nil
+	}
+
+	return mergeConfig(fs, &o.config, o.configPath)
+}
+
+// runCommand simulates the invasion configured by the flags
+func runCommand(e *env, args []string) int {
+	fs := e.flagSet("run", "")
+	opts := newOptions(fs)
+	printConfig := fs.Bool("print-config", false, "Print the effective config as JSON and exit")
+	fs.StringVar(&opts.config.Record, "record", "",
+		"Location of the file recording the config and the events of the run, for replay")
+	fs.StringVar(&opts.config.Format, "format", opts.config.Format,
+		"Report format ("+strings.Join(simulation.Formats, ", ")+")")
+
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() > 0 {
+		e.errorf("unexpected arguments %v", fs.Args())
+
+		return ExitUsage
+	}
+
+	if err := opts.load(fs); err != nil {
+		e.errorf("loading config err=%s", err.Error())
+
+		return exitCode(err)
+	}
+
+	if *printConfig {
+		if err := opts.config.Print(e.stdout); err != nil {
+			e.errorf("printing config err=%s", err.Error())
+
+			return ExitFailure
+		}
+
+		return ExitOK
+	}
+	// Validate the options
+	if err := opts.config.Validate(); err != nil {
+		e.errorf("validating options err=%s", err.Error())
+		fs.Usage()
+
+		return ExitUsage
+	}
+
+	// Only the text format streams the events, the others write nothing but the report
+	output := e.stdout
+	if opts.config.Format != simulation.FormatText {
+		output = ioutil.Discard
+	}
+
+	invasion, err := prepare(e, &opts.config, simulation.WithOutput(output))
+	if err != nil {
+		e.errorf("%s", err.Error())
+
+		return exitCode(err)
+	}
+
+	if opts.config.Format == simulation.FormatText {
+		fmt.Fprintln(e.stdout, "*****************************************")
+		fmt.Fprintln(e.stdout, "Aliens Started Invasion ...!!! ")
+		fmt.Fprintln(e.stdout, "Seed", opts.config.Seed)
+		fmt.Fprintln(e.stdout, "*****************************************")
+	}
+
+	// Start the simulation
+	stopped := invasion.simulator.Run(e.closeCh)
+
+	if opts.config.Format == simulation.FormatText {
+		printText(e, invasion)
+	} else {
+		report := invasion.simulator.Report()
+		report.Metadata.Map = opts.config.InputFile
+		report.Metadata.Seed = opts.config.Seed
+		report.Metadata.Stopped = errors.Is(stopped, simulation.ErrStopped)
+
+		if err := simulation.WriteReport(e.stdout, report, opts.config.Format); err != nil {
+			e.errorf("writing report err=%s", err.Error())
+
+			return ExitFailure
+		}
+	}
+
+	if len(opts.config.Record) > 0 {
+		if err := writeRecording(opts.config.Record, Recording{Config: opts.config, Events: invasion.simulator.Events()}); err != nil {
+			e.errorf("recording the run err=%s", err.Error())
+
+			return ExitFailure
+		}
+	}
+
+	if errors.Is(stopped, simulation.ErrStopped) {
+		return ExitCancelled
+	}
+
+	return ExitOK
+}
+
+// printText prints the cities left, the alien histories and, when relevant, the factions and the humans
+func printText(e *env, invasion *invasion) {
+	//Print the left over cities
+	simulation.PrintMap(e.stdout, invasion.world)
+	// Print the alien histories
+	simulation.PrintAliens(e.stdout, invasion.simulator.Aliens())
+
+	if invasion.withFactions {
+		simulation.PrintFactions(e.stdout, invasion.simulator.FactionResults())
+	}
+
+	if len(invasion.policies) > 0 {
+		simulation.PrintHumans(e.stdout, invasion.simulator.HumanResult())
+	}
+}
+
+// writeRecording writes the recording as JSON
+func writeRecording(filePath string, recording Recording) error {
+	data, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, append(data, '\n'), 0600)
+}
+
+// prepare builds the world map and the simulation configured by the options, with the extra
+// options. A time based seed is stored in the config when it has none.
+func prepare(e *env, config *Config, extra ...simulation.Option) (*invasion, error) {
+	// Build the world map
+	loadedMap, err := loadMap(config.InputFile, config.Directions)
+	if err != nil {
+		return nil, err
+	}
+
+	worldMap, cities := loadedMap.World, loadedMap.Cities
+
+	if config.CheckSymmetry {
+		for _, issue := range simulation.CheckSymmetry(worldMap, loadedMap.Directions) {
+			fmt.Fprintf(e.stderr, "Warning asymmetric road %s \n", issue)
+		}
+	}
+	// Resolve the placement strategy
+	strategy, err := simulation.ParsePlacement(config.Placement, config.Clusters, loadedMap.Directions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid placement: %w", err)
+	}
+	// Load the alien names
+	var names []string
+	if len(config.AlienNames) > 0 {
+		if names, err = simulation.LoadAlienNames(config.AlienNames); err != nil {
+			return nil, fmt.Errorf("loading alien names: %w", err)
+		}
+	}
+	// Resolve the alien classes
+	classes, err := simulation.ParseClassMix(config.Classes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing classes: %w", err)
+	}
+	// Resolve the human policies
+	policies, err := simulation.ParsePolicies(config.Policies)
+	if err != nil {
+		return nil, fmt.Errorf("parsing policies: %w", err)
+	}
+	// Resolve the road encounter rule
+	roadEncounter, err := config.encounter()
+	if err != nil {
+		return nil, fmt.Errorf("invalid road encounter: %w", err)
+	}
+	// Resolve the factions, rival factions are the only ones fighting
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+
+	opts := append([]simulation.Option{
+		simulation.WithCapacity(config.Capacity), simulation.WithPlacement(strategy), simulation.WithAlienNames(names),
+		simulation.WithBattleModel(config.battle()), simulation.WithDefenseModel(config.defense()),
+		simulation.WithRoadEncounter(roadEncounter), simulation.WithSimultaneousMoves(config.Simultaneous),
+		simulation.WithRoadDamage(config.roadDamage()), simulation.WithRecovery(config.Recovery),
+		simulation.WithEnergyModel(config.energy()), simulation.WithClassMix(classes),
+		simulation.WithPolicies(policies), simulation.WithDirections(loadedMap.Directions),
+		simulation.WithRand(rand.New(rand.NewSource(config.Seed))), //nolint:gosec
+	}, extra...)
+	withFactions := false
+
+	if len(config.Factions) > 0 {
+		factions, err := simulation.ParseFactions(config.Factions, config.Aliens)
+		if err != nil {
+			return nil, fmt.Errorf("parsing factions: %w", err)
+		}
+
+		opts = append(opts, simulation.WithFactions(factions))
+		withFactions = true
+	}
+
+	spawns, err := simulation.ParseSpawnSpec(config.Spawns)
+	if err != nil {
+		return nil, fmt.Errorf("parsing spawns: %w", err)
+	}
+
+	if len(config.SpawnFile) > 0 {
+		rules, err := simulation.LoadSpawnFile(config.SpawnFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading spawn file: %w", err)
+		}
+
+		spawns = append(spawns, rules...)
+	}
+
+	for _, rule := range spawns {
+		withFactions = withFactions || rule.Faction != ""
+	}
+
+	opts = append(opts, simulation.WithSpawns(spawns))
+
+	if len(config.Scenario) > 0 {
+		scenario, err := simulation.LoadScenario(config.Scenario)
+		if err != nil {
+			return nil, fmt.Errorf("loading scenario: %w", err)
+		}
+
+		for _, rule := range scenario {
+			withFactions = withFactions || rule.Faction() != ""
+		}
+
+		opts = append(opts, simulation.WithScenario(scenario))
+	}
+
+	bases, err := simulation.ParseMotherships(config.Motherships)
+	if err != nil {
+		return nil, fmt.Errorf("parsing motherships: %w", err)
+	}
+
+	bases = append(simulation.MapMotherships(cities), bases...)
+	for _, base := range bases {
+		withFactions = withFactions || base.Faction != ""
+	}
+
+	opts = append(opts, simulation.WithMotherships(bases, config.mothershipRule()))
+
+	if len(config.PlacementFile) > 0 {
+		entries, err := simulation.LoadPlacementFile(config.PlacementFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading placement file: %w", err)
+		}
+
+		for _, entry := range entries {
+			withFactions = withFactions || entry.Faction != ""
+		}
+
+		opts = append(opts, simulation.WithPinnedAliens(entries))
+	}
+
+	if withFactions {
+		opts = append(opts, simulation.WithFightRule(simulation.RivalFactions{}))
+	}
+	// Create Simulation instance
+	simulator, err := simulation.NewSimulation(worldMap, config.Aliens, config.Iterations, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating Simulation instance: %w", err)
+	}
+	// Allocate aliens to the cities
+	if err = simulator.InitAliens(cities, config.Aliens); err != nil {
+		return nil, fmt.Errorf("initiating aliens: %w", err)
+	}
+
+	return &invasion{simulator: simulator, world: worldMap, withFactions: withFactions, policies: policies}, nil
+
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/munna0908/alien-invasion/simulation"
+)
+
+// pathFlags are the flags holding a file path, resolved against the directory of the verification file
+var pathFlags = map[string]bool{
+	"input-file": true, "alien-names": true, "placement-file": true, "spawn-file": true, "scenario": true, "config": true,
+}
+
+// VerifyReport is the machine-readable outcome of a verification
+type VerifyReport struct {
+	Verification string                         `json:"verification"`
+	Seed         int64                          `json:"seed"`
+	Passed       bool                           `json:"passed"`
+	Results      []simulation.ExpectationResult `json:"results"`
+}
+
+// verifyCommand runs the seeded simulation set up by the verification file and
+// reports its expectations as JSON, the flags set the options left out of the file
+func verifyCommand(e *env, args []string) int {
+	fs := e.flagSet("verify", "<file>")
+	opts := newOptions(fs)
+
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return ExitUsage
+	}
+
+	filePath := fs.Arg(0)
+
+	verification, err := simulation.LoadVerification(filePath)
+	if err != nil {
+		e.errorf("loading verification err=%s", err.Error())
+
+		return ExitUsage
+	}
+
+	if err := applySettings(fs, verification.Settings, filepath.Dir(filePath)); err != nil {
+		e.errorf("applying verification settings err=%s", err.Error())
+
+		return ExitUsage
+	}
+
+	if err := opts.load(fs); err != nil {
+		e.errorf("loading config err=%s", err.Error())
+
+		return exitCode(err)
+	}
+
+	if err := opts.config.Validate(); err != nil {
+		e.errorf("validating verification settings err=%s", err.Error())
+
+		return ExitUsage
+	}
+
+	if opts.config.Seed == 0 {
+		e.errorf("validating verification settings err=seed: a verification needs a non zero seed")
+
+		return ExitUsage
+	}
+
+	invasion, err := prepare(e, &opts.config, simulation.WithOutput(ioutil.Discard))
+	if err != nil {
+		e.errorf("%s", err.Error())
+
+		return exitCode(err)
+	}
+
+	if errors.Is(invasion.simulator.Run(e.closeCh), simulation.ErrStopped) {
+		return ExitCancelled
+	}
+
+	report := VerifyReport{Verification: filePath, Seed: opts.config.Seed, Passed: true,
+		Results: invasion.simulator.Verify(verification.Expectations)}
+	for _, result := range report.Results {
+		report.Passed = report.Passed && result.Passed
+	}
+
+	encoder := json.NewEncoder(e.stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		e.errorf("writing verification report err=%s", err.Error())
+
+		return ExitFailure
+	}
+
+	if !report.Passed {
+		return ExitFailure
+	}
+
+	return ExitOK
+}
+
+// applySettings sets the flags named by the settings
+func applySettings(fs *flag.FlagSet, settings []simulation.Setting, dir string) error {
+	for _, setting := range settings {
+		value := setting.Value
+		if pathFlags[setting.Name] && !filepath.IsAbs(value) {
+			value = filepath.Join(dir, value)
+		}
+
+		if err := fs.Set(setting.Name, value); err != nil {
+			return fmt.Errorf("%s: %w", setting.Name, err)
+		}
+	}
+
+	return nil
+}
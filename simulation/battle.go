@@ -0,0 +1,112 @@
+package simulation
+
+import (
+	"errors"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidBattleModel = errors.New("invalid battle model")
+
+// Outcome is the result of a battle between aliens
+type Outcome int
+
+const (
+	// OutcomeDestroyed kills every alien and destroys the city
+	OutcomeDestroyed Outcome = iota
+	// OutcomeVictory leaves a single alien alive, the city survives
+	OutcomeVictory
+	// OutcomeDamaged kills every alien and damages the city, a damaged city is destroyed by the next damage
+	OutcomeDamaged
+	// OutcomeStalemate kills every alien, the city survives untouched
+	OutcomeStalemate
+)
+
+// BattleModel holds the probabilities of the battle outcomes, the remaining
+// probability destroys the city along with every alien in it.
+type BattleModel struct {
+	Victory   float64
+	Damaged   float64
+	Stalemate float64
+}
+
+// Validate checks that the probabilities are valid
+func (m BattleModel) Validate() error {
+	for _, p := range []float64{m.Victory, m.Damaged, m.Stalemate} {
+		if p < 0 || p > 1 {
+			return ErrInvalidBattleModel
+		}
+	}
+
+	if m.Victory+m.Damaged+m.Stalemate > 1 {
+		return ErrInvalidBattleModel
+	}
+
+	return nil
+}
+
+// roll draws the outcome of a battle from the simulation random source
+func (s *Simulation) roll() Outcome {
+	if s.battle == (BattleModel{}) {
+		return OutcomeDestroyed
+	}
+
+	r := s.rng.Float64()
+
+	switch {
+	case r < s.battle.Victory:
+		return OutcomeVictory
+	case r < s.battle.Victory+s.battle.Damaged:
+		return OutcomeDamaged
+	case r < s.battle.Victory+s.battle.Damaged+s.battle.Stalemate:
+		return OutcomeStalemate
+	}
+
+	return OutcomeDestroyed
+}
+
+// resolveFight settles the battle between the aliens occupying the city
+func (s *Simulation) resolveFight(city *types.City) {
+	if s.armoredFight(city) || s.absorbFight(city) {
+		return
+	}
+	// Only assaults and human strikes destroy a mothership
+	if s.findMothership(city) != nil {
+		aliens := s.killAliens(sortedAliens(city.OccupiedAliens))
+		s.emit(Event{Kind: EventStalemate, City: city.Name, Aliens: alienNames(aliens)})
+
+		return
+	}
+
+	switch s.roll() {
+	case OutcomeVictory:
+		aliens := sortedAliens(city.OccupiedAliens)
+		winner := aliens[s.rng.Intn(len(aliens))]
+		losers := make([]*types.Alien, 0, len(aliens)-1)
+
+		for _, alien := range aliens {
+			if alien != winner {
+				losers = append(losers, alien)
+			}
+		}
+
+		s.killAliens(losers)
+		s.emit(Event{Kind: EventVictory, City: city.Name, Aliens: alienNames(aliens),
+			Survivors: []string{winner.String()}})
+	case OutcomeDamaged:
+		if city.Damaged {
+			s.distroyCity(city)
+
+			return
+		}
+
+		city.Damaged = true
+		aliens := s.killAliens(sortedAliens(city.OccupiedAliens))
+		s.emit(Event{Kind: EventCityDamaged, City: city.Name, Aliens: alienNames(aliens)})
+	case OutcomeStalemate:
+		aliens := s.killAliens(sortedAliens(city.OccupiedAliens))
+		s.emit(Event{Kind: EventStalemate, City: city.Name, Aliens: alienNames(aliens)})
+	default:
+		s.distroyCity(city)
+	}
+}
@@ -0,0 +1,107 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBattleModelValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      BattleModel
+		shouldFail bool
+	}{
+		{name: "Default", model: BattleModel{}},
+		{name: "Valid", model: BattleModel{Victory: 0.2, Damaged: 0.3, Stalemate: 0.5}},
+		{name: "Negative", model: BattleModel{Victory: -0.1}, shouldFail: true},
+		{name: "Above one", model: BattleModel{Damaged: 1.5}, shouldFail: true},
+		{name: "Sum above one", model: BattleModel{Victory: 0.6, Stalemate: 0.6}, shouldFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.model.Validate()
+			if tt.shouldFail {
+				assert.ErrorIs(t, err, ErrInvalidBattleModel)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResolveFight(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     BattleModel
+		kinds     []EventKind
+		survivors int
+		destroyed bool
+	}{
+		{name: "Destroyed", model: BattleModel{}, kinds: []EventKind{EventCityDestroyed}, destroyed: true},
+		{name: "Victory", model: BattleModel{Victory: 1}, kinds: []EventKind{EventVictory}, survivors: 1},
+		{name: "Stalemate", model: BattleModel{Stalemate: 1}, kinds: []EventKind{EventStalemate}},
+		{
+			name:      "Damaged twice",
+			model:     BattleModel{Damaged: 1},
+			kinds:     []EventKind{EventCityDamaged, EventCityDestroyed},
+			destroyed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+				{1},
+				{0},
+			})
+			require.NoError(t, err)
+
+			simulation, err := NewSimulation(testWorld, 4, 10, WithBattleModel(tt.model), WithOutput(ioutil.Discard))
+			require.NoError(t, err)
+
+			for id := 0; len(simulation.events) < len(tt.kinds); id += 2 {
+				simulation.addAlien(types.NewAlien(id, "", cities[0]))
+				simulation.addAlien(types.NewAlien(id+1, "", cities[0]))
+				simulation.checkForFight()
+			}
+
+			events := simulation.Events()
+			for i, kind := range tt.kinds {
+				require.Equal(t, kind, events[i].Kind)
+				require.Equal(t, "testCity_0", events[i].City)
+			}
+
+			require.Len(t, simulation.aliens, tt.survivors)
+			require.Equal(t, tt.destroyed, testWorld.GetCity("testCity_0") == nil)
+		})
+	}
+}
+
+func TestSeededRunIsReproducible(t *testing.T) {
+	run := func() []Event {
+		testWorld, cities, err := createTestWorldWithNeighbours(4, [][]int{
+			{1, 2, 3},
+			{0, 2, 3},
+			{0, 1, 3},
+			{0, 1, 2},
+		})
+		require.NoError(t, err)
+
+		simulation, err := NewSimulation(testWorld, 6, 100, WithOutput(ioutil.Discard),
+			WithBattleModel(BattleModel{Victory: 0.4, Damaged: 0.3}), WithRand(rand.New(rand.NewSource(42)))) //nolint:gosec
+		require.NoError(t, err)
+		require.NoError(t, simulation.InitAliens(cities, 6))
+		simulation.Run(make(chan os.Signal))
+
+		return simulation.Events()
+	}
+
+	require.Equal(t, run(), run())
+}
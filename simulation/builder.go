@@ -3,7 +3,10 @@ package simulation
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/munna0908/alien-invasion/types"
@@ -15,91 +18,443 @@ var (
 	ErrInvalidNeighbour = errors.New("invalid neighbour")
 	ErrNoNeighbours     = errors.New("no neighbours")
 	ErrEmptyFile        = errors.New("empty file")
+	ErrInvalidAttribute = errors.New("invalid attribute")
+	ErrIncludeCycle     = errors.New("include cycle")
+	ErrUnknownDirective = errors.New("unknown directive")
+	ErrLateDirections   = errors.New("@directions must precede the cities")
+	ErrInvalidRoad      = errors.New("invalid road")
 )
 
+const (
+	// attributePrefix marks the free-form city attributes, e.g. @capital=true
+	attributePrefix = "@"
+	// directiveInclude parses another map file in place, e.g. @include europe.map
+	directiveInclude = "@include"
+	// directiveDirections selects the direction set of the map, e.g. @directions 8way
+	directiveDirections = "@directions"
+	commentPrefix       = "#"
+	byteOrderMark       = "\ufeff"
+)
+
+// Map is a world map along with the direction set used by its roads
+type Map struct {
+	World      types.World
+	Cities     []*types.City
+	Directions types.DirectionSet
+}
+
 // BuildMap reads the input file and create a map of cities
 func BuildMap(filePath string) (types.World, []*types.City, error) {
-	file, err := os.Open(filePath)
+	worldMap, err := LoadMap(filePath, types.FourWay)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "error reading file")
+		return nil, nil, err
 	}
 
-	st, err := file.Stat()
+	return worldMap.World, worldMap.Cities, nil
+}
+
+// LoadMap reads the input file and create a map of cities, the roads use the
+// given direction set unless the file selects another one with @directions.
+func LoadMap(filePath string, directions types.DirectionSet) (*Map, error) {
+	st, err := os.Stat(filePath)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "error reading file")
+		return nil, errors.Wrap(err, "error reading file")
 	}
 
 	if st.Size() == 0 {
-		return nil, nil, ErrEmptyFile
+		return nil, ErrEmptyFile
 	}
 	// Create a world map and cities instance
-	worldMap := types.NewWorldMap()
-	cities := make([]*types.City, 0)
+	builder := &mapBuilder{
+		worldMap:   types.NewWorldMap(),
+		cities:     make([]*types.City, 0),
+		directions: directions,
+		including:  make(map[string]bool),
+	}
+
+	if err := builder.parseFile(filePath); err != nil {
+		return nil, err
+	}
+
+	return &Map{World: builder.worldMap, Cities: builder.cities, Directions: builder.directions}, nil
+}
+
+// mapBuilder accumulates the cities of a map spread across included files
+type mapBuilder struct {
+	worldMap   types.World
+	cities     []*types.City
+	directions types.DirectionSet
+	// including holds the files being parsed, used to detect include cycles
+	including map[string]bool
+}
+
+// parseFile parses the map file, relative includes are resolved against its directory
+func (b *mapBuilder) parseFile(filePath string) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return errors.Wrap(err, "error reading file")
+	}
+
+	if b.including[absPath] {
+		return errors.Wrap(ErrIncludeCycle, filePath)
+	}
+
+	b.including[absPath] = true
+	defer delete(b.including, absPath)
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return errors.Wrap(err, "error reading file")
+	}
+	defer file.Close()
+
+	lineNumber := 0
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
+		lineNumber++
+
 		line := scanner.Text()
-		if line = strings.TrimRight(line, " "); len(line) == 0 {
-			continue
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, byteOrderMark)
 		}
 
-		tokens := strings.Split(line, " ")
-		if len(tokens) == 1 {
-			// City should have aleast one neighbour
-			return nil, nil, ErrNoNeighbours
+		tokens, err := tokenize(line)
+		if err != nil {
+			return errors.Wrapf(err, "%s:%d", filePath, lineNumber)
 		}
 
-		city := worldMap.GetCity(tokens[0])
-		if city == nil {
-			// If city doesnt exists create one
-			city = types.NewCity(tokens[0], len(tokens[1:]))
-			if err := worldMap.AddCity(city); err != nil {
-				return nil, nil, err
+		if len(tokens) == 0 {
+			continue
+		}
+
+		if isDirective(tokens[0], directiveInclude) {
+			if len(tokens) != 2 || tokens[1].hasValue {
+				return errors.Wrapf(ErrInvalidLine, "%s:%d", filePath, lineNumber)
 			}
 
-			cities = append(cities, city)
+			included := tokens[1].key
+			if !filepath.IsAbs(included) {
+				included = filepath.Join(filepath.Dir(absPath), included)
+			}
+
+			if err := b.parseFile(included); err != nil {
+				return errors.Wrapf(err, "%s:%d", filePath, lineNumber)
+			}
+
+			continue
 		}
 
-		for _, links := range tokens[1:] {
-			neighbours := strings.Split(links, "=")
-			if len(neighbours) != 2 || neighbours[1] == "" {
-				return nil, nil, ErrInvalidNeighbour
+		if isDirective(tokens[0], directiveDirections) {
+			if err := b.setDirections(tokens[1:]); err != nil {
+				return errors.Wrapf(err, "%s:%d", filePath, lineNumber)
 			}
-			// Parse the neighbours and create the cities if required
-			neighbourCity := worldMap.GetCity(neighbours[1])
-			if neighbourCity == nil {
-				neighbourCity = types.NewCity(neighbours[1], 0)
-				if err := worldMap.AddCity(neighbourCity); err != nil {
-					return nil, nil, err
-				}
-
-				cities = append(cities, neighbourCity)
+
+			continue
+		}
+
+		if !tokens[0].quoted && strings.HasPrefix(tokens[0].key, attributePrefix) {
+			return errors.Wrapf(ErrUnknownDirective, "%s:%d %s", filePath, lineNumber, tokens[0].key)
+		}
+
+		if err := b.parseLine(tokens); err != nil {
+			return errors.Wrapf(err, "%s:%d", filePath, lineNumber)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseLine adds the city described by the tokens and its neighbours to the map
+func (b *mapBuilder) parseLine(tokens []token) error {
+	if tokens[0].hasValue || tokens[0].key == "" {
+		// Names containing '=' have to be quoted
+		return ErrInvalidLine
+	}
+
+	if countDirections(tokens[1:]) == 0 {
+		// City should have aleast one neighbour
+		return ErrNoNeighbours
+	}
+
+	city := b.getOrAddCity(tokens[0].key, len(tokens[1:]))
+
+	for _, link := range tokens[1:] {
+		if isCustomAttribute(link) {
+			if err := setCustomAttribute(city, link); err != nil {
+				return err
 			}
-			// Add neighbours to the respective city
-			if err := city.AddNeighbour(neighbours[0], neighbourCity); err != nil {
-				return nil, nil, errors.Wrap(err, "error adding neighbour")
+
+			continue
+		}
+
+		if !link.hasValue || link.value == "" {
+			return ErrInvalidNeighbour
+		}
+
+		if isAttribute(link.key) {
+			if err := setAttribute(city, link.key, link.fullValue()); err != nil {
+				return err
 			}
+
+			continue
+		}
+		// Parse the neighbours and create the cities if required
+		neighbourCity := b.getOrAddCity(link.value, 0)
+		// Add neighbours to the respective city, the direction has to belong to the map direction set
+		if direction, err := types.ParseDirection(link.key); err == nil && !b.directions.Contains(direction) {
+			return errors.Wrapf(types.ErrInvalidDirection, "%s is not part of the %s directions", link.key, b.directions.Name)
+		}
+
+		if err := city.AddNeighbour(link.key, neighbourCity); err != nil {
+			return errors.Wrap(err, "error adding neighbour")
+		}
+
+		if err := setRoad(city, link); err != nil {
+			return err
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, nil, err
+	return nil
+}
+
+// setRoad stores the optional road weight and length following the neighbour, e.g. north=Bonn:3:2
+func setRoad(city *types.City, link token) error {
+	if len(link.params) > 2 {
+		return errors.Wrapf(ErrInvalidRoad, "%s=%s", link.key, link.fullValue())
 	}
 
-	return worldMap, cities, nil
+	values := make([]int, 0, len(link.params))
+
+	for _, param := range link.params {
+		value, err := strconv.Atoi(param)
+		if err != nil || value <= 0 {
+			return errors.Wrapf(ErrInvalidRoad, "%s=%s", link.key, link.fullValue())
+		}
+
+		values = append(values, value)
+	}
+
+	direction, _ := types.ParseDirection(link.key)
+
+	if len(values) > 0 {
+		city.SetRoadWeight(direction, values[0])
+	}
+
+	if len(values) > 1 {
+		city.SetRoadLength(direction, values[1])
+	}
+
+	return nil
+}
+
+// setDirections selects the direction set named by the @directions directive
+func (b *mapBuilder) setDirections(tokens []token) error {
+	if len(tokens) != 1 || tokens[0].hasValue {
+		return ErrInvalidLine
+	}
+
+	if len(b.cities) > 0 {
+		return ErrLateDirections
+	}
+
+	directions, err := types.GetDirectionSet(tokens[0].key)
+	if err != nil {
+		return errors.Wrap(err, tokens[0].key)
+	}
+
+	b.directions = directions
+
+	return nil
+}
+
+// getOrAddCity returns the city with the given name, the city is created if it doesnt exist
+func (b *mapBuilder) getOrAddCity(name string, neighboursCount int) *types.City {
+	city := b.worldMap.GetCity(name)
+	if city == nil {
+		city = types.NewCity(name, neighboursCount)
+		b.worldMap.AddCity(city) //nolint:errcheck // the city doesnt exist
+		b.cities = append(b.cities, city)
+	}
+
+	return city
+}
+
+// isDirective checks whether the token is the given directive
+func isDirective(t token, directive string) bool {
+	return !t.quoted && !t.hasValue && t.key == directive
+}
+
+// isCustomAttribute checks whether the token is a @key=value attribute
+func isCustomAttribute(t token) bool {
+	return !t.quoted && strings.HasPrefix(t.key, attributePrefix)
+}
+
+// isAttribute checks whether the key names a city attribute instead of a direction
+func isAttribute(key string) bool {
+	switch strings.ToLower(key) {
+	case types.AttributeDefense, types.AttributePopulation:
+		return true
+	}
+
+	return false
+}
+
+// countDirections returns the number of tokens which are not city attributes
+func countDirections(tokens []token) int {
+	count := 0
+
+	for _, t := range tokens {
+		if !isCustomAttribute(t) && !isAttribute(t.key) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// setAttribute parses the attribute value and stores it on the city
+func setAttribute(city *types.City, key, value string) error {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return errors.Wrapf(ErrInvalidAttribute, "%s=%s", key, value)
+	}
+
+	switch strings.ToLower(key) {
+	case types.AttributeDefense:
+		city.SetDefense(number)
+	case types.AttributePopulation:
+		city.SetPopulation(number)
+	}
+
+	return nil
+}
+
+// setCustomAttribute stores a @key=value token, a bare @key is a true boolean
+func setCustomAttribute(city *types.City, t token) error {
+	key, value := strings.TrimPrefix(t.key, attributePrefix), "true"
+	if t.hasValue {
+		value = t.fullValue()
+	}
+
+	if key == "" || value == "" {
+		return errors.Wrapf(ErrInvalidAttribute, "%s=%s", t.key, t.value)
+	}
+	// @defense and @population are the same attributes as defense and population
+	if isAttribute(key) {
+		return setAttribute(city, key, value)
+	}
+
+	city.SetAttribute(key, value)
+
+	return nil
+}
+
+// SymmetryIssue reports a road without a matching road in the opposite direction
+type SymmetryIssue struct {
+	City      string
+	Direction types.Direction
+	Neighbour string
+}
+
+// String implements the stringer interface
+func (i SymmetryIssue) String() string {
+	return fmt.Sprintf("%s %s=%s has no road back", types.QuoteName(i.City), types.GetDirection(i.Direction),
+		types.QuoteName(i.Neighbour))
+}
+
+// CheckSymmetry returns the roads whose destination does not link back in the opposite direction
+func CheckSymmetry(worldMap types.World, directions types.DirectionSet) []SymmetryIssue {
+	issues := make([]SymmetryIssue, 0)
+
+	for _, name := range worldMap.Names() {
+		city := worldMap.GetCity(name)
+
+		for _, direction := range directions.Directions {
+			neighbour := city.Neighbours[direction]
+			if neighbour == nil {
+				continue
+			}
+
+			if opposite, _ := directions.Opposite(direction); neighbour.Neighbours[opposite] != city {
+				issues = append(issues, SymmetryIssue{City: city.Name, Direction: direction, Neighbour: neighbour.Name})
+			}
+		}
+	}
+
+	return issues
 }
 
 // PrintMap prints the leftout cities
-func PrintMap(worldMap types.World) {
-	fmt.Println("*****************************************")
-	fmt.Println("Cities Left After Invasion")
-	fmt.Println("*****************************************")
+func PrintMap(w io.Writer, worldMap types.World) {
+	fmt.Fprintln(w, "*****************************************")
+	fmt.Fprintln(w, "Cities Left After Invasion")
+	fmt.Fprintln(w, "*****************************************")
+
+	for _, name := range worldMap.Names() {
+		if city := worldMap.GetCity(name); city != nil && city.State != types.Ruins {
+			fmt.Fprintln(w, city.String())
+		}
+	}
+
+	fmt.Fprintln(w)
+}
+
+// PrintAliens prints the history of every alien
+func PrintAliens(w io.Writer, aliens []*types.Alien) {
+	fmt.Fprintln(w, "*****************************************")
+	fmt.Fprintln(w, "Alien Histories")
+	fmt.Fprintln(w, "*****************************************")
+
+	for _, alien := range aliens {
+		fmt.Fprintf(w, "%s [%d]", alien.String(), alien.ID)
+
+		if alien.Class != types.Regular {
+			fmt.Fprintf(w, " %s", alien.Class)
+		}
 
-	for _, city := range worldMap {
-		if city != nil {
-			fmt.Println(city.String())
+		fmt.Fprintf(w, " (%s) spawned in %s, %d moves: %s",
+			alien.Status, alien.Spawn.Name, alien.Moves, strings.Join(alien.Path, " -> "))
+
+		if alien.Status != types.Dead {
+			fmt.Fprintf(w, ", now %s", alien.Position())
 		}
+
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// PrintFactions prints the outcome of the invasion per faction
+func PrintFactions(w io.Writer, results []FactionResult) {
+	fmt.Fprintln(w, "*****************************************")
+	fmt.Fprintln(w, "Factions")
+	fmt.Fprintln(w, "*****************************************")
+
+	for _, result := range results {
+		fmt.Fprintf(w, "%s: %d aliens, %d alive, %d trapped, %d dead, %d cities destroyed\n",
+			result.Faction, result.Aliens, result.Alive, result.Trapped, result.Dead, result.CitiesDestroyed)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// PrintHumans prints the outcome of the invasion for the humans
+func PrintHumans(w io.Writer, result HumanResult) {
+	fmt.Fprintln(w, "*****************************************")
+	fmt.Fprintln(w, "Human Response")
+	fmt.Fprintln(w, "*****************************************")
+	fmt.Fprintf(w, "Population: %d before the invasion, %d in the cities left, %d evacuated\n",
+		result.Population, result.Surviving, result.Evacuated)
+
+	if len(result.Quarantined) > 0 {
+		fmt.Fprintf(w, "Quarantined: %s\n", strings.Join(result.Quarantined, ", "))
+	}
+
+	if len(result.Sacrificed) > 0 {
+		fmt.Fprintf(w, "Sacrificed: %s\n", strings.Join(result.Sacrificed, ", "))
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
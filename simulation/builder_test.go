@@ -3,6 +3,7 @@ package simulation
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/munna0908/alien-invasion/types"
@@ -73,6 +74,18 @@ func TestBuildMapInvalidLine(t *testing.T) {
 			err:        ErrInvalidNeighbour,
 			data:       "Banglore north=",
 		},
+		{
+			name:       "Invalid Attribute",
+			shouldFail: true,
+			err:        ErrInvalidAttribute,
+			data:       "Banglore north=hyderabad defense=high",
+		},
+		{
+			name:       "Attributes Without Neighbours",
+			shouldFail: true,
+			err:        ErrNoNeighbours,
+			data:       "Banglore defense=3",
+		},
 		{
 			name:       "Invalid Direction",
 			shouldFail: true,
@@ -98,6 +111,231 @@ func TestBuildMapInvalidLine(t *testing.T) {
 	}
 }
 
+func TestBuildMapAttributes(t *testing.T) {
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte("Paris north=Lyon defense=3 population=2000000\n"), 0600)
+	require.NoError(t, err)
+
+	worldMap, _, err := BuildMap(fileName)
+	require.NoError(t, err)
+
+	paris := worldMap.GetCity("Paris")
+	require.Equal(t, 3, paris.Defense())
+	require.Equal(t, 2000000, paris.Population())
+	require.Equal(t, "Paris north=Lyon defense=3 population=2000000 ", paris.String())
+	// The defense and the population are typed attributes, e.g. for the attribute placement
+	population, ok := paris.IntAttribute(types.AttributePopulation)
+	require.True(t, ok)
+	require.Equal(t, 2000000, population)
+	// @defense is the same attribute as defense, it must be a non negative integer too
+	err = os.WriteFile(fileName, []byte("Paris north=Lyon @defense=4\n"), 0600)
+	require.NoError(t, err)
+
+	worldMap, _, err = BuildMap(fileName)
+	require.NoError(t, err)
+	require.Equal(t, 4, worldMap.GetCity("Paris").Defense())
+	require.Equal(t, "Paris north=Lyon defense=4 ", worldMap.GetCity("Paris").String())
+
+	err = os.WriteFile(fileName, []byte("Paris north=Lyon @defense=high\n"), 0600)
+	require.NoError(t, err)
+
+	_, _, err = BuildMap(fileName)
+	require.ErrorIs(t, err, ErrInvalidAttribute)
+}
+
+func TestBuildMapCustomAttributes(t *testing.T) {
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte("Paris north=Lyon @population=5 @capital @mayor=Anne @area=105.4\n"), 0600)
+	require.NoError(t, err)
+
+	worldMap, _, err := BuildMap(fileName)
+	require.NoError(t, err)
+
+	paris := worldMap.GetCity("Paris")
+	population, ok := paris.IntAttribute("population")
+	require.True(t, ok)
+	require.Equal(t, 5, population)
+
+	capital, ok := paris.BoolAttribute("capital")
+	require.True(t, ok)
+	require.True(t, capital)
+
+	area, ok := paris.FloatAttribute("area")
+	require.True(t, ok)
+	require.Equal(t, 105.4, area)
+
+	_, ok = paris.IntAttribute("mayor")
+	require.False(t, ok)
+
+	mayor, ok := paris.StringAttribute("mayor")
+	require.True(t, ok)
+	require.Equal(t, "Anne", mayor)
+
+	require.Equal(t, 5, paris.Population())
+	require.Equal(t, "Paris north=Lyon population=5 @area=105.4 @capital=true @mayor=Anne ", paris.String())
+
+	err = os.WriteFile(fileName, []byte("Paris north=Lyon @=5\n"), 0600)
+	require.NoError(t, err)
+
+	_, _, err = BuildMap(fileName)
+	require.ErrorIs(t, err, ErrInvalidAttribute)
+}
+
+func TestBuildMapFormatting(t *testing.T) {
+	data := "\ufeff# Lower saxony\r\n" +
+		"\r\n" +
+		"Hannover\tnorth=Bremen   south=Kassel # trailing comment\r\n" +
+		"   \t\r\n" +
+		"Bremen south=Hannover\r\n"
+
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte(data), 0600)
+	require.NoError(t, err)
+
+	worldMap, cities, err := BuildMap(fileName)
+	require.NoError(t, err)
+	require.Len(t, cities, 3)
+	require.Equal(t, "Hannover north=Bremen south=Kassel ", worldMap.GetCity("Hannover").String())
+}
+
+func TestBuildMapInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "regions"), 0700))
+
+	files := map[string]string{
+		"world.map":         "@include regions/north.map\nHannover north=Bremen\n",
+		"regions/north.map": "Bremen north=Kiel\n@include coast.map # relative to regions\n",
+		"regions/coast.map": "Kiel east=Lubeck\n",
+		"cycle.map":         "Hannover north=Bremen\n@include regions/cycle.map\n",
+		"regions/cycle.map": "Bremen north=Kiel\n@include ../cycle.map\n",
+		"directive.map":     "@teleport Hannover\n",
+	}
+
+	for name, data := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0600))
+	}
+
+	worldMap, _, err := BuildMap(filepath.Join(dir, "world.map"))
+	require.NoError(t, err)
+
+	for _, name := range []string{"Hannover", "Bremen", "Kiel", "Lubeck"} {
+		require.Contains(t, worldMap, name)
+	}
+
+	_, _, err = BuildMap(filepath.Join(dir, "cycle.map"))
+	require.ErrorIs(t, err, ErrIncludeCycle)
+
+	_, _, err = BuildMap(filepath.Join(dir, "directive.map"))
+	require.ErrorIs(t, err, ErrUnknownDirective)
+}
+
+func TestBuildMapQuotedNames(t *testing.T) {
+	// Zürich is written in its decomposed form, u followed by a combining diaeresis
+	data := `"New York" north="Jersey City" south="Zu\u0308rich" @motto="Big Apple"` + "\n" +
+		`"Jersey City" south="New York"` + "\n"
+
+	_, fileName := createTempFile(t)
+	require.NoError(t, os.WriteFile(fileName, []byte(data), 0600))
+
+	worldMap, cities, err := BuildMap(fileName)
+	require.NoError(t, err)
+	require.Len(t, cities, 3)
+	// Lookups use the NFC form
+	require.NotNil(t, worldMap.GetCity("Z\u00fcrich"))
+	require.NotNil(t, worldMap.GetCity("Zu\u0308rich"))
+
+	newYork := worldMap.GetCity("New York")
+	require.Equal(t, `"New York" north="Jersey City" south=Zürich @motto="Big Apple" `, newYork.String())
+
+	// The written map can be read back
+	_, roundTrip := createTempFile(t)
+	require.NoError(t, os.WriteFile(roundTrip, []byte(newYork.String()+"\n"), 0600))
+
+	worldMap, _, err = BuildMap(roundTrip)
+	require.NoError(t, err)
+	require.Equal(t, newYork.String(), worldMap.GetCity("New York").String())
+}
+
+func TestLoadMapDirections(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		directions types.DirectionSet
+		expected   types.DirectionSet
+		err        error
+	}{
+		{name: "Default", data: "A north=B\n", directions: types.FourWay, expected: types.FourWay},
+		{name: "Flag", data: "A northeast=B up=C\n", directions: types.EightWay, err: types.ErrInvalidDirection},
+		{name: "Header", data: "# grid\n@directions hex\nA northeast=B\n", directions: types.FourWay, expected: types.Hex},
+		{name: "Header with vertical links", data: "@directions 3d\nA up=B\n", directions: types.FourWay, expected: types.ThreeD},
+		{name: "Outside of the set", data: "A northeast=B\n", directions: types.FourWay, err: types.ErrInvalidDirection},
+		{name: "Unknown set", data: "@directions 12way\nA north=B\n", directions: types.FourWay, err: types.ErrUnknownDirectionSet},
+		{name: "Late header", data: "A north=B\n@directions 8way\n", directions: types.FourWay, err: ErrLateDirections},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, fileName := createTempFile(t)
+			require.NoError(t, os.WriteFile(fileName, []byte(tt.data), 0600))
+
+			worldMap, err := LoadMap(fileName, tt.directions)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.expected.Name, worldMap.Directions.Name)
+			}
+		})
+	}
+}
+
+func TestCheckSymmetry(t *testing.T) {
+	_, fileName := createTempFile(t)
+	data := "@directions 8way\nA northeast=B east=C\nB southwest=A\nC east=A\n"
+	require.NoError(t, os.WriteFile(fileName, []byte(data), 0600))
+
+	worldMap, err := LoadMap(fileName, types.FourWay)
+	require.NoError(t, err)
+
+	issues := CheckSymmetry(worldMap.World, worldMap.Directions)
+	require.Len(t, issues, 2)
+	require.Equal(t, "A east=C has no road back", issues[0].String())
+	require.Equal(t, "C east=A has no road back", issues[1].String())
+}
+
+func TestBuildMapRoadWeights(t *testing.T) {
+	_, fileName := createTempFile(t)
+	data := `Bonn north=Koln:3 south="Bad:Ems" east=Siegen:1 @opening=08:30` + "\n"
+	require.NoError(t, os.WriteFile(fileName, []byte(data), 0600))
+
+	worldMap, _, err := BuildMap(fileName)
+	require.NoError(t, err)
+
+	bonn := worldMap.GetCity("Bonn")
+	require.Equal(t, 3, bonn.RoadWeight(types.North))
+	require.Equal(t, types.DefaultRoadWeight, bonn.RoadWeight(types.South))
+	require.Equal(t, types.DefaultRoadWeight, bonn.RoadWeight(types.East))
+	require.NotNil(t, worldMap.GetCity("Bad:Ems"))
+	require.Equal(t, `Bonn north=Koln:3 south="Bad:Ems" east=Siegen @opening="08:30" `, bonn.String())
+
+	require.NoError(t, os.WriteFile(fileName, []byte("Bonn north=Koln:2:5 south=Siegen:1:3\n"), 0600))
+
+	worldMap, _, err = BuildMap(fileName)
+	require.NoError(t, err)
+
+	bonn = worldMap.GetCity("Bonn")
+	require.Equal(t, 5, bonn.RoadLength(types.North))
+	require.Equal(t, 3, bonn.RoadLength(types.South))
+	require.Equal(t, "Bonn north=Koln:2:5 south=Siegen:1:3 ", bonn.String())
+
+	for _, data := range []string{"Bonn north=Koln:0\n", "Bonn north=Koln:x\n", "Bonn north=Koln:1:2:3\n"} {
+		require.NoError(t, os.WriteFile(fileName, []byte(data), 0600))
+
+		_, _, err = BuildMap(fileName)
+		require.ErrorIs(t, err, ErrInvalidRoad, data)
+	}
+}
+
 // createTempFile creates a temporary file and removes the file after test execution
 func createTempFile(t *testing.T) (*os.File, string) {
 	t.Helper()
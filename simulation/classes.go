@@ -0,0 +1,147 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidClassMix = errors.New("invalid class mix")
+
+// ClassMix holds the relative weight of every alien class, the aliens are all
+// regular when it is empty.
+type ClassMix map[types.AlienClass]int
+
+// ParseClassMix parses the class weights separated by ',', e.g. "regular:7,scout:2,tank",
+// a class without a weight counts once.
+func ParseClassMix(spec string) (ClassMix, error) {
+	mix := make(ClassMix)
+
+	for _, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidClassMix, entry)
+		}
+
+		class, err := types.ParseAlienClass(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidClassMix, err.Error())
+		}
+
+		weight := 1
+		if len(parts) == 2 {
+			if weight, err = strconv.Atoi(parts[1]); err != nil || weight < 0 {
+				return nil, fmt.Errorf("%w: invalid weight %q", ErrInvalidClassMix, entry)
+			}
+		}
+
+		mix[class] += weight
+	}
+
+	return mix, nil
+}
+
+// pickClass draws the class of a new alien from the class mix
+func (s *Simulation) pickClass() types.AlienClass {
+	total := 0
+	for _, weight := range s.classes {
+		total += weight
+	}
+
+	if total == 0 {
+		return types.Regular
+	}
+
+	target := s.rng.Intn(total)
+	for _, class := range types.AlienClasses {
+		if target -= s.classes[class]; target < 0 {
+			return class
+		}
+	}
+
+	return types.Regular
+}
+
+// shouldFight applies the fight rule, the stealth aliens never fight each other
+func (s *Simulation) shouldFight(aliens map[int]*types.Alien) bool {
+	stealth := 0
+
+	for _, alien := range aliens {
+		if alien.Class == types.Stealth {
+			stealth++
+		}
+	}
+
+	if stealth > 0 && stealth == len(aliens) {
+		return false
+	}
+
+	return s.fightRule.ShouldFight(aliens)
+}
+
+// armorSaves spends the armor of the aliens caught in a fight and returns the ones it saved
+func armorSaves(aliens []*types.Alien) []*types.Alien {
+	saved := make([]*types.Alien, 0)
+
+	for _, alien := range aliens {
+		if alien.Armor > 0 {
+			alien.Armor--
+			saved = append(saved, alien)
+		}
+	}
+
+	return saved
+}
+
+// armoredFight lets the armored aliens survive the fight in the city, the
+// other aliens die and the city stands.
+func (s *Simulation) armoredFight(city *types.City) bool {
+	aliens := sortedAliens(city.OccupiedAliens)
+
+	saved := armorSaves(aliens)
+	if len(saved) == 0 {
+		return false
+	}
+
+	s.killAliens(losers(aliens, saved))
+	s.emit(Event{Kind: EventArmorSaved, City: city.Name, Aliens: alienNames(aliens), Survivors: alienNames(saved)})
+
+	return true
+}
+
+// bomb lets a bomber destroy the city it enters, motherships only fall to assaults and strikes
+func (s *Simulation) bomb(city *types.City, alien *types.Alien) bool {
+	if alien.Class != types.Bomber || s.findMothership(city) != nil {
+		return false
+	}
+
+	s.emit(Event{Kind: EventBombing, City: city.Name, Aliens: []string{alien.String()}})
+	s.distroyCity(city)
+
+	return true
+}
+
+// losers returns the aliens not among the survivors
+func losers(aliens, survivors []*types.Alien) []*types.Alien {
+	saved := make(map[int]bool, len(survivors))
+	for _, alien := range survivors {
+		saved[alien.ID] = true
+	}
+
+	dead := make([]*types.Alien, 0, len(aliens))
+
+	for _, alien := range aliens {
+		if !saved[alien.ID] {
+			dead = append(dead, alien)
+		}
+	}
+
+	return dead
+}
@@ -0,0 +1,144 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClassMix(t *testing.T) {
+	mix, err := ParseClassMix("regular:7, Scout:2,tank")
+	require.NoError(t, err)
+	require.Equal(t, ClassMix{types.Regular: 7, types.Scout: 2, types.Tank: 1}, mix)
+
+	for _, spec := range []string{"ninja", "tank:x", "tank:-1", "tank:1:2"} {
+		_, err = ParseClassMix(spec)
+		require.ErrorIs(t, err, ErrInvalidClassMix, spec)
+	}
+}
+
+func TestClassMix(t *testing.T) {
+	testWorld, cities := createTestWorld(2)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithClassMix(ClassMix{types.Tank: 1}))
+	require.NoError(t, err)
+
+	alien := simulation.newAlien(0, cities[0])
+	require.Equal(t, types.Tank, alien.Class)
+	require.Equal(t, 1, alien.Armor)
+
+	simulation, err = NewSimulation(testWorld, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, types.Regular, simulation.newAlien(0, cities[0]).Class)
+}
+
+// createClassSimulation creates a simulation on a line of cities, aliens of the given classes
+// are added to the first city.
+func createClassSimulation(t *testing.T, classes ...types.AlienClass) (*Simulation, []*types.City) {
+	t.Helper()
+
+	testWorld, cities, err := createTestWorldWithNeighbours(3, [][]int{
+		{1},
+		{2},
+		{},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithCapacity(3), WithOutput(ioutil.Discard),
+		WithDefenseModel(DefenseModel{}))
+	require.NoError(t, err)
+
+	for id, class := range classes {
+		alien := types.NewAlien(id, "", cities[0])
+		alien.Class = class
+
+		if class == types.Tank {
+			alien.Armor = 1
+		}
+
+		simulation.addAlien(alien)
+	}
+
+	return simulation, cities
+}
+
+func TestScout(t *testing.T) {
+	simulation, cities := createClassSimulation(t, types.Scout)
+
+	simulation.moveAliens()
+	require.Equal(t, cities[2], simulation.aliens.GetAlien(0).City)
+	require.Equal(t, 2, simulation.aliens.GetAlien(0).Moves)
+}
+
+func TestScoutOnLongRoad(t *testing.T) {
+	for _, simultaneous := range []bool{false, true} {
+		testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+			{1},
+			{0},
+		})
+		require.NoError(t, err)
+		cities[0].SetRoadLength(types.North, 2)
+		cities[1].SetRoadLength(types.North, 2)
+
+		simulation, err := NewSimulation(testWorld, 1, 10, WithSimultaneousMoves(simultaneous),
+			WithOutput(ioutil.Discard))
+		require.NoError(t, err)
+
+		alien := types.NewAlien(0, "", cities[0])
+		alien.Class = types.Scout
+		simulation.addAlien(alien)
+
+		simulation.moveAliens()
+		require.True(t, alien.OnRoad())
+		// Arriving from a long road ends the move, there is no second hop
+		simulation.moveAliens()
+		require.False(t, alien.OnRoad(), "simultaneous: %v", simultaneous)
+		require.Equal(t, cities[1], alien.City, "simultaneous: %v", simultaneous)
+		require.Equal(t, 1, alien.Moves, "simultaneous: %v", simultaneous)
+	}
+}
+
+func TestTank(t *testing.T) {
+	simulation, cities := createClassSimulation(t, types.Tank, types.Regular)
+
+	simulation.checkForFight()
+	require.Equal(t, []int{0}, simulation.aliens.IDs())
+	require.Equal(t, 0, simulation.aliens.GetAlien(0).Armor)
+	require.NotNil(t, simulation.worldMap.GetCity(cities[0].Name))
+	require.Equal(t, EventArmorSaved, simulation.Events()[0].Kind)
+	// The armor is spent, the next fight is an ordinary one
+	alien := types.NewAlien(1, "", cities[0])
+	simulation.addAlien(alien)
+	simulation.checkForFight()
+	require.Empty(t, simulation.aliens)
+	require.Nil(t, simulation.worldMap.GetCity(cities[0].Name))
+}
+
+func TestBomber(t *testing.T) {
+	simulation, cities := createClassSimulation(t, types.Bomber)
+
+	simulation.moveAliens()
+	require.Empty(t, simulation.aliens)
+	require.Nil(t, simulation.worldMap.GetCity(cities[1].Name))
+
+	kinds := make([]EventKind, 0)
+	for _, event := range simulation.Events() {
+		kinds = append(kinds, event.Kind)
+	}
+
+	require.Equal(t, []EventKind{EventBombing, EventCityDestroyed}, kinds)
+}
+
+func TestStealth(t *testing.T) {
+	simulation, _ := createClassSimulation(t, types.Stealth, types.Stealth)
+
+	simulation.checkForFight()
+	require.Len(t, simulation.aliens, 2)
+
+	simulation, _ = createClassSimulation(t, types.Stealth, types.Regular)
+
+	simulation.checkForFight()
+	require.Empty(t, simulation.aliens)
+}
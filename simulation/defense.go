@@ -0,0 +1,60 @@
+package simulation
+
+import (
+	"errors"
+	"math"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidDefenseModel = errors.New("invalid defense model")
+
+// DefaultDefenseModel is used unless the simulation is configured otherwise
+var DefaultDefenseModel = DefenseModel{Kill: 0.1, Absorb: 0.15}
+
+// DefenseModel holds the probabilities, per defense level, that a city kills an
+// arriving alien or absorbs a battle. An absorbed battle kills every alien,
+// the city survives and loses a defense level.
+type DefenseModel struct {
+	Kill   float64
+	Absorb float64
+}
+
+// Validate checks that the probabilities are valid
+func (m DefenseModel) Validate() error {
+	if m.Kill < 0 || m.Kill > 1 || m.Absorb < 0 || m.Absorb > 1 {
+		return ErrInvalidDefenseModel
+	}
+
+	return nil
+}
+
+// chance returns the probability for the given defense level
+func (m DefenseModel) chance(perLevel float64, city *types.City) float64 {
+	return math.Min(1, perLevel*float64(city.Defense()))
+}
+
+// repelAlien gives the defenses of the city a chance to kill the arriving alien
+func (s *Simulation) repelAlien(city *types.City, alien *types.Alien) bool {
+	if city.Defense() <= 0 || s.rng.Float64() >= s.defense.chance(s.defense.Kill, city) {
+		return false
+	}
+
+	s.killAliens([]*types.Alien{alien})
+	s.emit(Event{Kind: EventDefenseKill, City: city.Name, Aliens: []string{alien.String()}})
+
+	return true
+}
+
+// absorbFight gives the defenses of the city a chance to absorb the battle
+func (s *Simulation) absorbFight(city *types.City) bool {
+	if city.Defense() <= 0 || s.rng.Float64() >= s.defense.chance(s.defense.Absorb, city) {
+		return false
+	}
+
+	city.SetDefense(city.Defense() - 1)
+	aliens := s.killAliens(sortedAliens(city.OccupiedAliens))
+	s.emit(Event{Kind: EventDefenseAbsorb, City: city.Name, Aliens: alienNames(aliens)})
+
+	return true
+}
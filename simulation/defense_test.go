@@ -0,0 +1,63 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefenseKillsArrivingAlien(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	cities[1].SetDefense(1)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithDefenseModel(DefenseModel{Kill: 1}),
+		WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+	simulation.addAlien(types.NewAlien(0, "", cities[0]))
+
+	simulation.moveAliens()
+
+	require.Empty(t, simulation.aliens)
+	require.Empty(t, cities[1].OccupiedAliens)
+	require.Equal(t, EventDefenseKill, simulation.Events()[0].Kind)
+}
+
+func TestDefenseAbsorbsFight(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	cities[0].SetDefense(1)
+
+	simulation, err := NewSimulation(testWorld, 2, 10, WithDefenseModel(DefenseModel{Absorb: 1}),
+		WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+
+	for round := 0; round < 2; round++ {
+		simulation.addAlien(types.NewAlien(2*round, "", cities[0]))
+		simulation.addAlien(types.NewAlien(2*round+1, "", cities[0]))
+		simulation.checkForFight()
+	}
+
+	// The first battle wears the defenses down, the second one destroys the city
+	events := simulation.Events()
+	require.Len(t, events, 2)
+	require.Equal(t, EventDefenseAbsorb, events[0].Kind)
+	require.Equal(t, EventCityDestroyed, events[1].Kind)
+	require.Equal(t, 0, cities[0].Defense())
+}
+
+func TestDefenseModelValidate(t *testing.T) {
+	require.NoError(t, DefaultDefenseModel.Validate())
+	require.ErrorIs(t, DefenseModel{Kill: 2}.Validate(), ErrInvalidDefenseModel)
+	require.ErrorIs(t, DefenseModel{Absorb: -1}.Validate(), ErrInvalidDefenseModel)
+}
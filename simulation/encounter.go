@@ -0,0 +1,167 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidRoadEncounter = errors.New("invalid road encounter")
+
+// EncounterOutcome is the result of aliens crossing each other on a road
+type EncounterOutcome int
+
+const (
+	// EncounterNone lets the aliens pass each other, this is the default
+	EncounterNone EncounterOutcome = iota
+	// EncounterFight kills every alien involved
+	EncounterFight
+	// EncounterDuel leaves a single alien alive, it carries on its way
+	EncounterDuel
+)
+
+// RoadEncounter settles the rival aliens crossing each other on a road, either
+// travelling a multi-tick road in opposite directions or swapping cities in
+// the same tick with simultaneous moves.
+type RoadEncounter struct {
+	Outcome EncounterOutcome
+	// DestroyRoad is the probability that the road is destroyed by the encounter
+	DestroyRoad float64
+}
+
+// ParseEncounterOutcome returns the outcome registered under the given name
+func ParseEncounterOutcome(name string) (EncounterOutcome, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return EncounterNone, nil
+	case "fight":
+		return EncounterFight, nil
+	case "duel":
+		return EncounterDuel, nil
+	}
+
+	return EncounterNone, fmt.Errorf("%w: unknown outcome %s", ErrInvalidRoadEncounter, name)
+}
+
+// Validate checks that the encounter rule is valid
+func (r RoadEncounter) Validate() error {
+	if r.Outcome < EncounterNone || r.Outcome > EncounterDuel || r.DestroyRoad < 0 || r.DestroyRoad > 1 {
+		return ErrInvalidRoadEncounter
+	}
+
+	return nil
+}
+
+// resolveEncounter settles the encounter of the aliens on the road between the
+// two cities and returns the survivors.
+func (s *Simulation) resolveEncounter(aliens []*types.Alien, from, to *types.City) []*types.Alien {
+	group := make(map[int]*types.Alien, len(aliens))
+	for _, alien := range aliens {
+		group[alien.ID] = alien
+	}
+
+	if s.encounter.Outcome == EncounterNone || !s.shouldFight(group) {
+		return aliens
+	}
+
+	if saved := armorSaves(aliens); len(saved) > 0 {
+		s.killAliens(losers(aliens, saved))
+		s.emit(Event{Kind: EventArmorSaved, From: from.Name, To: to.Name, Aliens: alienNames(aliens),
+			Survivors: alienNames(saved)})
+
+		return saved
+	}
+
+	survivors := make([]*types.Alien, 0, 1)
+
+	if s.encounter.Outcome == EncounterDuel {
+		winner := aliens[s.rng.Intn(len(aliens))]
+		losers := make([]*types.Alien, 0, len(aliens)-1)
+
+		for _, alien := range aliens {
+			if alien != winner {
+				losers = append(losers, alien)
+			}
+		}
+
+		survivors = append(survivors, winner)
+		s.killAliens(losers)
+		s.emit(Event{Kind: EventRoadDuel, From: from.Name, To: to.Name, Aliens: alienNames(aliens),
+			Survivors: alienNames(survivors)})
+	} else {
+		s.killAliens(aliens)
+		s.emit(Event{Kind: EventRoadFight, From: from.Name, To: to.Name, Aliens: alienNames(aliens)})
+	}
+
+	if s.encounter.DestroyRoad > 0 && s.rng.Float64() < s.encounter.DestroyRoad {
+		s.destroyRoad(from, to, aliens)
+	}
+
+	return survivors
+}
+
+// moveAliensSimultaneously lets every alien choose its road before any of them
+// moves, the aliens swapping cities meet on the road before they arrive.
+func (s *Simulation) moveAliensSimultaneously() {
+	ids := s.aliens.IDs()
+	arriving := make(map[int]bool, len(ids))
+	// hopping are the aliens leaving a city on a single tick road, only they can take a second hop
+	hopping := make(map[int]bool, len(ids))
+
+	for _, id := range ids {
+		alien := s.aliens.GetAlien(id)
+		if alien.OnRoad() {
+			arriving[id] = true
+
+			continue
+		}
+
+		if s.rests(alien) {
+			continue
+		}
+
+		currentCity := alien.City
+
+		direction, err := currentCity.PickRandomDirection(s.rng)
+		if err != nil {
+			// Alien is trapped
+			alien.Status = types.Trapped
+			s.recoverEnergy(alien)
+
+			continue
+		}
+
+		if s.exhaust(alien, currentCity, direction) {
+			continue
+		}
+		// Single tick roads are travelled within this iteration
+		length := currentCity.RoadLength(direction)
+		arriving[id] = length == 1
+		hopping[id] = length == 1
+		newCity := currentCity.Neighbours[direction]
+		s.damageRoad(alien, currentCity, direction)
+
+		currentCity.RemoveAlien(id)
+		alien.Depart(direction, newCity, length-1)
+	}
+
+	if s.encounter.Outcome != EncounterNone {
+		s.checkForRoadFights()
+	}
+
+	for _, id := range ids {
+		// The alien may have died on the road
+		if alien := s.aliens.GetAlien(id); alien != nil && arriving[id] {
+			s.travel(alien)
+		}
+	}
+	// Scouts which left a city take their second hop once everyone has moved, as in the sequential moves
+	for _, id := range ids {
+		alien := s.aliens.GetAlien(id)
+		if alien != nil && alien.Class == types.Scout && hopping[id] && !alien.OnRoad() {
+			s.moveAlien(alien)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package simulation
+
+import (
+	"errors"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidEnergyModel = errors.New("invalid energy model")
+
+// EnergyModel gives the aliens a limited amount of energy. Every move costs
+// MoveCost, multiplied by the road weight when Weighted, and an alien dies of
+// exhaustion when paying for a move leaves it without energy. The aliens not
+// moving, either trapped or resting by choice with RestChance, regain Rest
+// energy up to Max. A zero Max disables the model.
+type EnergyModel struct {
+	Max        int
+	MoveCost   int
+	Rest       int
+	RestChance float64
+	Weighted   bool
+}
+
+// Validate checks that the model is valid
+func (m EnergyModel) Validate() error {
+	if m.Max < 0 || m.MoveCost < 0 || m.Rest < 0 || m.RestChance < 0 || m.RestChance > 1 {
+		return ErrInvalidEnergyModel
+	}
+
+	return nil
+}
+
+// enabled checks whether the aliens use energy at all
+func (m EnergyModel) enabled() bool {
+	return m.Max > 0
+}
+
+// rests gives the alien a chance to rest instead of moving this iteration
+func (s *Simulation) rests(alien *types.Alien) bool {
+	if !s.energy.enabled() || s.energy.RestChance == 0 || s.rng.Float64() >= s.energy.RestChance {
+		return false
+	}
+
+	s.recoverEnergy(alien)
+
+	return true
+}
+
+// recoverEnergy gives energy back to the alien not moving this iteration
+func (s *Simulation) recoverEnergy(alien *types.Alien) {
+	if !s.energy.enabled() {
+		return
+	}
+
+	if alien.Energy += s.energy.Rest; alien.Energy > s.energy.Max {
+		alien.Energy = s.energy.Max
+	}
+}
+
+// exhaust makes the alien pay for taking the road, the alien dies of exhaustion
+// where it stands when it runs out of energy.
+func (s *Simulation) exhaust(alien *types.Alien, from *types.City, direction types.Direction) bool {
+	if !s.energy.enabled() {
+		return false
+	}
+
+	cost := s.energy.MoveCost
+	if s.energy.Weighted {
+		cost *= from.RoadWeight(direction)
+	}
+
+	if alien.Energy -= cost; alien.Energy > 0 {
+		return false
+	}
+
+	s.killAliens([]*types.Alien{alien})
+	s.emit(Event{Kind: EventExhausted, City: from.Name, Aliens: []string{alien.String()}})
+
+	return true
+}
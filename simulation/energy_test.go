@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnergyExhaustion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		model    EnergyModel
+		weight   int
+		moves    int
+		expected string
+	}{
+		{name: "disabled", model: EnergyModel{}, moves: 5},
+		{name: "exhausted on the third move", model: EnergyModel{Max: 3, MoveCost: 1}, moves: 2, expected: "testCity_0"},
+		{
+			name: "weighted cost", model: EnergyModel{Max: 3, MoveCost: 1, Weighted: true}, weight: 2, moves: 1,
+			expected: "testCity_1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+				{1},
+				{0},
+			})
+			require.NoError(t, err)
+
+			if tc.weight > 0 {
+				cities[0].SetRoadWeight(types.North, tc.weight)
+				cities[1].SetRoadWeight(types.North, tc.weight)
+			}
+
+			simulation, err := NewSimulation(testWorld, 1, 10, WithEnergyModel(tc.model), WithOutput(ioutil.Discard))
+			require.NoError(t, err)
+
+			alien := simulation.newAlien(0, cities[0])
+			simulation.addAlien(alien)
+			require.Equal(t, tc.model.Max, alien.Energy)
+
+			for i := 0; i < 5; i++ {
+				simulation.moveAliens()
+			}
+
+			require.Equal(t, tc.moves, alien.Moves)
+
+			if tc.model.Max == 0 {
+				require.Equal(t, types.Alive, alien.Status)
+
+				return
+			}
+
+			require.Equal(t, types.Dead, alien.Status)
+			require.Empty(t, simulation.aliens)
+			require.Empty(t, alien.City.OccupiedAliens)
+
+			events := simulation.Events()
+			require.Len(t, events, 1)
+			require.Equal(t, EventExhausted, events[0].Kind)
+			require.Equal(t, tc.expected, events[0].City)
+		})
+	}
+}
+
+func TestEnergyRest(t *testing.T) {
+	testWorld, cities := createTestWorld(1)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard),
+		WithEnergyModel(EnergyModel{Max: 5, MoveCost: 1, Rest: 2}))
+	require.NoError(t, err)
+
+	alien := simulation.newAlien(0, cities[0])
+	alien.Energy = 2
+	simulation.addAlien(alien)
+
+	// A trapped alien regains energy up to the maximum
+	simulation.moveAliens()
+	require.Equal(t, types.Trapped, alien.Status)
+	require.Equal(t, 4, alien.Energy)
+
+	simulation.moveAliens()
+	require.Equal(t, 5, alien.Energy)
+
+	_, err = NewSimulation(testWorld, 1, 10, WithEnergyModel(EnergyModel{Max: 5, RestChance: 2}))
+	require.ErrorIs(t, err, ErrInvalidEnergyModel)
+}
@@ -0,0 +1,159 @@
+package simulation
+
+import (
+	"fmt"
+)
+
+// EventKind identifies what happened during the invasion
+type EventKind string
+
+const (
+	EventCityDestroyed   EventKind = "city_destroyed"
+	EventVictory         EventKind = "victory"
+	EventCityDamaged     EventKind = "city_damaged"
+	EventStalemate       EventKind = "stalemate"
+	EventDefenseKill     EventKind = "defense_kill"
+	EventDefenseAbsorb   EventKind = "defense_absorb"
+	EventRoadFight       EventKind = "road_fight"
+	EventRoadDuel        EventKind = "road_duel"
+	EventRoadDestroyed   EventKind = "road_destroyed"
+	EventRoadBlocked     EventKind = "road_blocked"
+	EventRoadReopened    EventKind = "road_reopened"
+	EventRoadAdded       EventKind = "road_added"
+	EventCityRebuilt     EventKind = "city_rebuilt"
+	EventSpawn           EventKind = "spawn"
+	EventSpawnBlocked    EventKind = "spawn_blocked"
+	EventLaunch          EventKind = "launch"
+	EventLaunchBlocked   EventKind = "launch_blocked"
+	EventAssault         EventKind = "assault"
+	EventHumanStrike     EventKind = "human_strike"
+	EventExhausted       EventKind = "exhausted"
+	EventArmorSaved      EventKind = "armor_saved"
+	EventBombing         EventKind = "bombing"
+	EventQuarantine      EventKind = "quarantine"
+	EventEvacuation      EventKind = "evacuation"
+	EventSacrifice       EventKind = "sacrifice"
+	EventScenarioSkipped EventKind = "scenario_skipped"
+)
+
+// Event records a change of the world during the invasion
+type Event struct {
+	Iteration int       `json:"iteration"`
+	Kind      EventKind `json:"kind"`
+	City      string    `json:"city,omitempty"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	Aliens    []string  `json:"aliens,omitempty"`
+	Survivors []string  `json:"survivors,omitempty"`
+	Count     int       `json:"count,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// String implements the stringer interface
+func (e Event) String() string {
+	switch e.Kind {
+	case EventCityDestroyed:
+		if len(e.Aliens) == 0 {
+			return fmt.Sprintf("%s has been destroyed ! ", e.City)
+		}
+
+		return fmt.Sprintf("%s has been destroyed by %s ! ", e.City, joinNames(e.Aliens))
+	case EventVictory:
+		return fmt.Sprintf("%s won the battle for %s against %s ! ",
+			joinNames(e.Survivors), e.City, joinNames(without(e.Aliens, e.Survivors)))
+	case EventCityDamaged:
+		return fmt.Sprintf("%s has been damaged in a battle between %s ! ", e.City, joinNames(e.Aliens))
+	case EventStalemate:
+		return fmt.Sprintf("%s killed each other in %s, the city survived ! ", joinNames(e.Aliens), e.City)
+	case EventDefenseKill:
+		return fmt.Sprintf("%s defenses killed %s on arrival ! ", e.City, joinNames(e.Aliens))
+	case EventDefenseAbsorb:
+		return fmt.Sprintf("%s defenses absorbed the battle between %s ! ", e.City, joinNames(e.Aliens))
+	case EventRoadFight:
+		return fmt.Sprintf("%s killed each other on the road between %s and %s ! ", joinNames(e.Aliens), e.From, e.To)
+	case EventRoadDuel:
+		return fmt.Sprintf("%s won the duel on the road between %s and %s against %s ! ",
+			joinNames(e.Survivors), e.From, e.To, joinNames(without(e.Aliens, e.Survivors)))
+	case EventRoadDestroyed:
+		if len(e.Aliens) > 0 {
+			return fmt.Sprintf("The road between %s and %s has been destroyed by %s ! ", e.From, e.To, joinNames(e.Aliens))
+		}
+
+		return fmt.Sprintf("The road between %s and %s has been destroyed ! ", e.From, e.To)
+	case EventRoadBlocked:
+		return fmt.Sprintf("The road between %s and %s has been blocked ! ", e.From, e.To)
+	case EventRoadReopened:
+		return fmt.Sprintf("The road between %s and %s has been reopened ! ", e.From, e.To)
+	case EventRoadAdded:
+		return fmt.Sprintf("A road from %s to %s has been built ! ", e.From, e.To)
+	case EventCityRebuilt:
+		return fmt.Sprintf("%s has been rebuilt from its ruins ! ", e.City)
+	case EventSpawn:
+		return fmt.Sprintf("%s landed in %s ! ", joinNames(e.Aliens), e.City)
+	case EventSpawnBlocked:
+		if e.City == "" {
+			return fmt.Sprintf("%d aliens could not land, every city is full ! ", e.Count)
+		}
+
+		return fmt.Sprintf("%d aliens could not land in %s ! ", e.Count, e.City)
+	case EventLaunch:
+		return fmt.Sprintf("The mothership in %s launched %s into %s ! ", e.City, joinNames(e.Aliens), e.To)
+	case EventLaunchBlocked:
+		return fmt.Sprintf("The mothership in %s could not launch an alien, no neighbour has room left ! ", e.City)
+	case EventAssault:
+		return fmt.Sprintf("%s assaulted the mothership in %s, %d assaults so far ! ", joinNames(e.Aliens), e.City, e.Count)
+	case EventHumanStrike:
+		return fmt.Sprintf("Humans struck the mothership in %s ! ", e.City)
+	case EventArmorSaved:
+		place := e.City
+		if place == "" {
+			place = fmt.Sprintf("the road between %s and %s", e.From, e.To)
+		}
+
+		return fmt.Sprintf("%s survived the fight in %s thanks to their armor ! ", joinNames(e.Survivors), place)
+	case EventBombing:
+		return fmt.Sprintf("%s blew itself up in %s ! ", joinNames(e.Aliens), e.City)
+	case EventQuarantine:
+		return fmt.Sprintf("%s has been quarantined ! ", e.City)
+	case EventEvacuation:
+		return fmt.Sprintf("%d people have been evacuated from %s to %s ! ", e.Count, e.From, e.To)
+	case EventSacrifice:
+		return fmt.Sprintf("Humans sacrificed %s to kill %s ! ", e.City, joinNames(e.Aliens))
+	case EventScenarioSkipped:
+		return fmt.Sprintf("Scenario rule %s ! ", e.Detail)
+	case EventExhausted:
+		return fmt.Sprintf("%s died of exhaustion in %s ! ", joinNames(e.Aliens), e.City)
+	}
+
+	return fmt.Sprintf("%s in %s", e.Kind, e.City)
+}
+
+// emit records the event and reports it on the simulation output
+func (s *Simulation) emit(event Event) {
+	event.Iteration = s.count
+	s.events = append(s.events, event)
+	fmt.Fprintln(s.out, event.String())
+}
+
+// Events returns the events recorded so far, in order of occurrence
+func (s *Simulation) Events() []Event {
+	return append([]Event(nil), s.events...)
+}
+
+// without returns the names not present in the excluded list
+func without(names, excluded []string) []string {
+	skip := make(map[string]bool, len(excluded))
+	for _, name := range excluded {
+		skip[name] = true
+	}
+
+	kept := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if !skip[name] {
+			kept = append(kept, name)
+		}
+	}
+
+	return kept
+}
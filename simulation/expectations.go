@@ -0,0 +1,340 @@
+package simulation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidExpectation = errors.New("invalid expectation")
+
+const (
+	// settingPrefix starts the lines of a verification file setting a run option, e.g. @seed 42
+	settingPrefix  = "@"
+	stateStanding  = "standing"
+	stateDestroyed = "destroyed"
+)
+
+// Verification is a seeded run along with the expectations its outcome is checked against
+type Verification struct {
+	Settings     []Setting
+	Expectations []Expectation
+}
+
+// Setting is a run option of a verification, named after the command line flag
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// Expectation is a declarative statement about the outcome of a run, e.g. "Berlin survives"
+type Expectation struct {
+	Text string
+	// check returns the expected and the actual values, and whether they match
+	check func(s *Simulation) (string, string, bool)
+}
+
+// ExpectationResult is the outcome of an expectation, the values differ when it failed
+type ExpectationResult struct {
+	Expectation string `json:"expectation"`
+	Passed      bool   `json:"passed"`
+	Expected    string `json:"expected"`
+	Actual      string `json:"actual"`
+}
+
+// bound compares a count with a limit, e.g. "at most 3"
+type bound struct {
+	kind  string
+	limit int
+}
+
+// LoadVerification reads a verification file, the lines starting with '@' set a run option
+// and the other ones are expectations. Blank lines and '#' comments are ignored.
+func LoadVerification(filePath string) (Verification, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Verification{}, fmt.Errorf("error reading file: %w", err)
+	}
+	defer file.Close()
+
+	verification := Verification{}
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+
+		tokens, err := tokenize(scanner.Text())
+		if err != nil {
+			return Verification{}, fmt.Errorf("%s:%d: %w", filePath, lineNumber, err)
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		if !tokens[0].quoted && strings.HasPrefix(tokens[0].key, settingPrefix) {
+			if len(tokens) != 2 || tokens[0].hasValue || tokens[1].hasValue {
+				return Verification{}, fmt.Errorf("%s:%d: %w: invalid setting", filePath, lineNumber, ErrInvalidExpectation)
+			}
+
+			verification.Settings = append(verification.Settings,
+				Setting{Name: strings.TrimPrefix(tokens[0].key, settingPrefix), Value: tokens[1].key})
+
+			continue
+		}
+
+		expectation, err := parseExpectation(tokens)
+		if err != nil {
+			return Verification{}, fmt.Errorf("%s:%d: %w: %q", filePath, lineNumber, err, scanner.Text())
+		}
+
+		expectation.Text = strings.TrimSpace(strings.SplitN(scanner.Text(), commentPrefix, 2)[0])
+		verification.Expectations = append(verification.Expectations, expectation)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Verification{}, err
+	}
+
+	return verification, nil
+}
+
+// ParseExpectation parses an expectation, one of
+//
+//	<city> survives | <city> destroyed
+//	<at most|at least|exactly> <n> cities destroyed [by iteration <k>]
+//	<at most|at least|exactly> <n> aliens left
+//	alien <id> <alive|trapped|dead> | alien <id> in <city>
+func ParseExpectation(line string) (Expectation, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return Expectation{}, err
+	}
+
+	expectation, err := parseExpectation(tokens)
+	if err != nil {
+		return Expectation{}, err
+	}
+
+	expectation.Text = strings.TrimSpace(strings.SplitN(line, commentPrefix, 2)[0])
+
+	return expectation, nil
+}
+
+func parseExpectation(tokens []token) (Expectation, error) {
+	args := make([]string, 0, len(tokens))
+
+	for _, token := range tokens {
+		if token.hasValue {
+			return Expectation{}, ErrInvalidExpectation
+		}
+
+		args = append(args, token.key)
+	}
+
+	if len(args) > 0 && args[0] == "alien" && !tokens[0].quoted {
+		return parseAlienExpectation(args[1:])
+	}
+
+	if b, rest, ok := parseBound(args); ok {
+		return parseCountExpectation(b, rest)
+	}
+
+	if len(args) == 2 && (args[1] == "survives" || args[1] == stateDestroyed) {
+		name, expected := args[0], stateStanding
+		if args[1] == stateDestroyed {
+			expected = stateDestroyed
+		}
+
+		return Expectation{check: func(s *Simulation) (string, string, bool) {
+			actual := s.cityState(name)
+
+			return expected, actual, actual == expected
+		}}, nil
+	}
+
+	return Expectation{}, ErrInvalidExpectation
+}
+
+// parseAlienExpectation parses the "<id> <status>" or "<id> in <city>" arguments of an alien expectation
+func parseAlienExpectation(args []string) (Expectation, error) {
+	if len(args) < 2 {
+		return Expectation{}, ErrInvalidExpectation
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id < 0 {
+		return Expectation{}, ErrInvalidExpectation
+	}
+
+	if len(args) == 3 && args[1] == "in" {
+		name := types.NormalizeName(args[2])
+
+		return Expectation{check: func(s *Simulation) (string, string, bool) {
+			actual := s.alienLocation(id)
+
+			return name, actual, actual == name
+		}}, nil
+	}
+
+	if len(args) != 2 {
+		return Expectation{}, ErrInvalidExpectation
+	}
+
+	for _, status := range []types.AlienStatus{types.Alive, types.Trapped, types.Dead} {
+		if status.String() != args[1] {
+			continue
+		}
+
+		return Expectation{check: func(s *Simulation) (string, string, bool) {
+			actual := "unknown alien"
+			if id < len(s.roster) {
+				actual = s.roster[id].Status.String()
+			}
+
+			return status.String(), actual, actual == status.String()
+		}}, nil
+	}
+
+	return Expectation{}, ErrInvalidExpectation
+}
+
+// parseCountExpectation parses the "cities destroyed [by iteration <k>]" or "aliens left" end of a count expectation
+func parseCountExpectation(b bound, args []string) (Expectation, error) {
+	switch {
+	case len(args) == 2 && args[0] == "aliens" && args[1] == "left":
+		return Expectation{check: func(s *Simulation) (string, string, bool) {
+			return b.String(), strconv.Itoa(len(s.aliens)), b.holds(len(s.aliens))
+		}}, nil
+	case len(args) >= 2 && args[0] == "cities" && args[1] == stateDestroyed:
+		by := -1
+
+		if len(args) == 5 && args[2] == "by" && args[3] == "iteration" {
+			iteration, err := strconv.Atoi(args[4])
+			if err != nil || iteration < 0 {
+				return Expectation{}, ErrInvalidExpectation
+			}
+
+			by = iteration
+		} else if len(args) != 2 {
+			return Expectation{}, ErrInvalidExpectation
+		}
+
+		return Expectation{check: func(s *Simulation) (string, string, bool) {
+			destroyed := 0
+
+			for _, event := range s.events {
+				if event.Kind == EventCityDestroyed && (by < 0 || event.Iteration <= by) {
+					destroyed++
+				}
+			}
+
+			return b.String(), strconv.Itoa(destroyed), b.holds(destroyed)
+		}}, nil
+	}
+
+	return Expectation{}, ErrInvalidExpectation
+}
+
+// parseBound parses the leading "at most <n>", "at least <n>" or "exactly <n>" arguments
+func parseBound(args []string) (bound, []string, bool) {
+	b := bound{}
+
+	switch {
+	case len(args) > 2 && args[0] == "at" && (args[1] == "most" || args[1] == "least"):
+		b.kind, args = "at "+args[1], args[2:]
+	case len(args) > 1 && args[0] == "exactly":
+		b.kind, args = args[0], args[1:]
+	default:
+		return bound{}, nil, false
+	}
+
+	limit, err := strconv.Atoi(args[0])
+	if err != nil || limit < 0 {
+		return bound{}, nil, false
+	}
+
+	b.limit = limit
+
+	return b, args[1:], true
+}
+
+// String implements the stringer interface
+func (b bound) String() string {
+	return fmt.Sprintf("%s %d", b.kind, b.limit)
+}
+
+// holds checks whether the count is within the bound
+func (b bound) holds(count int) bool {
+	switch b.kind {
+	case "at most":
+		return count <= b.limit
+	case "at least":
+		return count >= b.limit
+	}
+
+	return count == b.limit
+}
+
+// Check checks the expectation against the outcome of the simulation
+func (e Expectation) Check(s *Simulation) ExpectationResult {
+	expected, actual, passed := e.check(s)
+
+	return ExpectationResult{Expectation: e.Text, Passed: passed, Expected: expected, Actual: actual}
+}
+
+// Verify checks the expectations against the outcome of the simulation, in order
+func (s *Simulation) Verify(expectations []Expectation) []ExpectationResult {
+	results := make([]ExpectationResult, 0, len(expectations))
+
+	for _, expectation := range expectations {
+		results = append(results, expectation.Check(s))
+	}
+
+	return results
+}
+
+// cityState tells whether the city is standing or was destroyed, ruins count as destroyed
+func (s *Simulation) cityState(name string) string {
+	if city := s.worldMap.GetCity(name); city != nil {
+		if s.standing(city) {
+			return stateStanding
+		}
+
+		return stateDestroyed
+	}
+
+	for _, event := range s.events {
+		if event.Kind == EventCityDestroyed && event.City == types.NormalizeName(name) {
+			return stateDestroyed
+		}
+	}
+
+	return "unknown city"
+}
+
+// alienLocation returns the city the alien is in, or what keeps it out of a city
+func (s *Simulation) alienLocation(id int) string {
+	if id >= len(s.roster) {
+		return "unknown alien"
+	}
+
+	alien := s.roster[id]
+
+	switch {
+	case alien.Status == types.Dead:
+		return alien.Status.String()
+	case alien.OnRoad():
+		return "on the road"
+	case alien.City == nil:
+		return "nowhere"
+	}
+
+	return alien.City.Name
+}
@@ -0,0 +1,113 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpectation(t *testing.T) {
+	for _, line := range []string{
+		"Berlin survives",
+		`"New York" destroyed # flooded`,
+		"at most 3 cities destroyed by iteration 100",
+		"at least 1 cities destroyed",
+		"exactly 2 aliens left",
+		"alien 4 trapped",
+		"alien 0 in Berlin",
+	} {
+		expectation, err := ParseExpectation(line)
+		require.NoError(t, err, line)
+		require.NotNil(t, expectation.check, line)
+	}
+
+	for _, line := range []string{
+		"Berlin thrives",
+		"at most three cities destroyed",
+		"at most 3 cities destroyed by 100",
+		"alien four trapped",
+		"alien 4 lost",
+		"alien 4 in",
+		"exactly 2 aliens",
+		"Berlin=Paris survives",
+	} {
+		_, err := ParseExpectation(line)
+		require.ErrorIs(t, err, ErrInvalidExpectation, line)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(3, [][]int{
+		{1},
+		{0, 2},
+		{1},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 2, 10, WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+
+	first := simulation.newAlien(0, cities[1])
+	second := simulation.newAlien(1, cities[1])
+	simulation.addAlien(first)
+	simulation.addAlien(second)
+
+	simulation.count = 3
+	simulation.resolveFight(cities[1])
+
+	third := simulation.newAlien(2, cities[2])
+	third.Status = types.Trapped
+	simulation.addAlien(third)
+
+	expectations := make([]Expectation, 0)
+
+	for _, line := range []string{
+		"testCity_0 survives",
+		"testCity_1 survives",
+		"exactly 1 cities destroyed by iteration 3",
+		"at least 1 cities destroyed by iteration 2",
+		"alien 0 dead",
+		"alien 2 trapped",
+		"alien 2 in testCity_2",
+		"alien 7 alive",
+		"at most 1 aliens left",
+	} {
+		expectation, err := ParseExpectation(line)
+		require.NoError(t, err)
+
+		expectations = append(expectations, expectation)
+	}
+
+	require.Equal(t, []ExpectationResult{
+		{Expectation: "testCity_0 survives", Passed: true, Expected: "standing", Actual: "standing"},
+		{Expectation: "testCity_1 survives", Expected: "standing", Actual: "destroyed"},
+		{Expectation: "exactly 1 cities destroyed by iteration 3", Passed: true, Expected: "exactly 1", Actual: "1"},
+		{Expectation: "at least 1 cities destroyed by iteration 2", Expected: "at least 1", Actual: "0"},
+		{Expectation: "alien 0 dead", Passed: true, Expected: "dead", Actual: "dead"},
+		{Expectation: "alien 2 trapped", Passed: true, Expected: "trapped", Actual: "trapped"},
+		{Expectation: "alien 2 in testCity_2", Passed: true, Expected: "testCity_2", Actual: "testCity_2"},
+		{Expectation: "alien 7 alive", Expected: "alive", Actual: "unknown alien"},
+		{Expectation: "at most 1 aliens left", Passed: true, Expected: "at most 1", Actual: "1"},
+	}, simulation.Verify(expectations))
+}
+
+func TestLoadVerification(t *testing.T) {
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte("# regression\n@seed 42\n@spawns \"every 5: spawn 1\"\n\nBonn survives # capital\n"), 0600)
+	require.NoError(t, err)
+
+	verification, err := LoadVerification(fileName)
+	require.NoError(t, err)
+	require.Equal(t, []Setting{{Name: "seed", Value: "42"}, {Name: "spawns", Value: "every 5: spawn 1"}}, verification.Settings)
+	require.Len(t, verification.Expectations, 1)
+	require.Equal(t, "Bonn survives", verification.Expectations[0].Text)
+
+	err = os.WriteFile(fileName, []byte("@seed\n"), 0600)
+	require.NoError(t, err)
+
+	_, err = LoadVerification(fileName)
+	require.ErrorIs(t, err, ErrInvalidExpectation)
+}
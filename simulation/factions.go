@@ -0,0 +1,138 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidFactions = errors.New("invalid factions")
+
+// FightRule decides whether the aliens gathered in a city fight each other
+type FightRule interface {
+	ShouldFight(aliens map[int]*types.Alien) bool
+}
+
+// CoLocation makes any two aliens sharing a city fight, this is the default rule
+type CoLocation struct{}
+
+func (CoLocation) ShouldFight(aliens map[int]*types.Alien) bool {
+	return len(aliens) > 1
+}
+
+// RivalFactions lets the aliens of a faction share a city peacefully,
+// only the aliens of rival factions fight. The aliens without a faction are
+// rivals of every faction.
+type RivalFactions struct{}
+
+func (RivalFactions) ShouldFight(aliens map[int]*types.Alien) bool {
+	if len(aliens) < 2 {
+		return false
+	}
+
+	sorted := sortedAliens(aliens)
+	for _, alien := range sorted[1:] {
+		if alien.Faction != sorted[0].Faction {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FactionResult summarises the outcome of the invasion for a faction
+type FactionResult struct {
+	Faction         string
+	Aliens          int
+	Alive           int
+	Trapped         int
+	Dead            int
+	CitiesDestroyed int
+}
+
+// ParseFactions assigns a faction to each alien following the spec.
+// The spec is either a list of names, e.g. "red,blue", assigned round-robin,
+// or a list of counts, e.g. "red:3,blue:2", which must add up to the aliens count.
+func ParseFactions(spec string, aliensCount int) ([]string, error) {
+	entries := strings.Split(spec, ",")
+	names := make([]string, 0, len(entries))
+	counts := make([]int, 0, len(entries))
+
+	for _, entry := range entries {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) > 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidFactions, entry)
+		}
+
+		names = append(names, parts[0])
+
+		if len(parts) == 2 {
+			count, err := strconv.Atoi(parts[1])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("%w: invalid count %q", ErrInvalidFactions, entry)
+			}
+
+			counts = append(counts, count)
+		}
+	}
+
+	factions := make([]string, 0, aliensCount)
+
+	switch len(counts) {
+	case 0:
+		for i := 0; i < aliensCount; i++ {
+			factions = append(factions, names[i%len(names)])
+		}
+	case len(names):
+		for i, name := range names {
+			for j := 0; j < counts[i]; j++ {
+				factions = append(factions, name)
+			}
+		}
+
+		if len(factions) != aliensCount {
+			return nil, fmt.Errorf("%w: counts add up to %d instead of %d", ErrInvalidFactions, len(factions), aliensCount)
+		}
+	default:
+		return nil, fmt.Errorf("%w: either all or none of the factions need a count", ErrInvalidFactions)
+	}
+
+	return factions, nil
+}
+
+// FactionResults returns the outcome of the invasion per faction, ordered by name
+func (s *Simulation) FactionResults() []FactionResult {
+	results := make(map[string]*FactionResult)
+
+	for _, alien := range s.roster {
+		result, ok := results[alien.Faction]
+		if !ok {
+			result = &FactionResult{Faction: alien.Faction, CitiesDestroyed: s.destroyedBy[alien.Faction]}
+			results[alien.Faction] = result
+		}
+
+		result.Aliens++
+
+		switch alien.Status {
+		case types.Alive:
+			result.Alive++
+		case types.Trapped:
+			result.Trapped++
+		case types.Dead:
+			result.Dead++
+		}
+	}
+
+	summary := make([]FactionResult, 0, len(results))
+	for _, result := range results {
+		summary = append(summary, *result)
+	}
+
+	sort.Slice(summary, func(i, j int) bool { return summary[i].Faction < summary[j].Faction })
+
+	return summary
+}
@@ -0,0 +1,121 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFactions(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		aliensCount int
+		shouldFail  bool
+		expected    []string
+	}{
+		{name: "Round robin", spec: "red,blue", aliensCount: 3, expected: []string{"red", "blue", "red"}},
+		{name: "Counts", spec: "red:1, blue:2", aliensCount: 3, expected: []string{"red", "blue", "blue"}},
+		{name: "Counts mismatch", spec: "red:1,blue:1", aliensCount: 3, shouldFail: true},
+		{name: "Mixed counts", spec: "red:1,blue", aliensCount: 3, shouldFail: true},
+		{name: "Invalid count", spec: "red:x", aliensCount: 3, shouldFail: true},
+		{name: "Empty name", spec: "red,,blue", aliensCount: 3, shouldFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factions, err := ParseFactions(tt.spec, tt.aliensCount)
+			if tt.shouldFail {
+				assert.ErrorIs(t, err, ErrInvalidFactions)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, factions)
+			}
+		})
+	}
+}
+
+func TestRivalFactions(t *testing.T) {
+	city := types.NewCity("Paris", 0)
+	red := types.NewAlien(0, "", city)
+	red.Faction = "red"
+	ally := types.NewAlien(1, "", city)
+	ally.Faction = "red"
+	blue := types.NewAlien(2, "", city)
+	blue.Faction = "blue"
+
+	rule := RivalFactions{}
+	require.False(t, rule.ShouldFight(map[int]*types.Alien{0: red}))
+	require.False(t, rule.ShouldFight(map[int]*types.Alien{0: red, 1: ally}))
+	require.True(t, rule.ShouldFight(map[int]*types.Alien{0: red, 1: ally, 2: blue}))
+	require.True(t, CoLocation{}.ShouldFight(map[int]*types.Alien{0: red, 1: ally}))
+	// An alien without a faction is a rival of every faction, whatever the map order
+	loner := types.NewAlien(3, "", city)
+	for i := 0; i < 100; i++ {
+		require.True(t, rule.ShouldFight(map[int]*types.Alien{3: loner, 0: red}))
+		require.True(t, rule.ShouldFight(map[int]*types.Alien{3: loner, 0: red, 1: ally}))
+	}
+
+	require.False(t, rule.ShouldFight(map[int]*types.Alien{}))
+}
+
+func TestFactionsShareCities(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 4, 10, WithCapacity(4),
+		WithPinnedAliens([]PlacementEntry{
+			{City: "testCity_0", Faction: "red"},
+			{City: "testCity_0", Faction: "red"},
+			{City: "testCity_1"},
+			{City: "testCity_1"},
+		}),
+		WithFactions([]string{"", "", "blue", "blue"}),
+		WithFightRule(RivalFactions{}))
+	require.NoError(t, err)
+	require.NoError(t, simulation.InitAliens(cities, 4))
+
+	// Allies share the cities peacefully
+	simulation.checkForFight()
+	require.Len(t, simulation.aliens, 4)
+	// The first alien to move meets its rivals
+	simulation.moveAliens()
+	require.Len(t, testWorld, 1)
+
+	results := simulation.FactionResults()
+	require.Len(t, results, 2)
+
+	for i, faction := range []string{"blue", "red"} {
+		require.Equal(t, faction, results[i].Faction)
+		require.Equal(t, 2, results[i].Aliens)
+		require.Equal(t, 1, results[i].CitiesDestroyed)
+	}
+
+	require.Equal(t, 3, results[0].Dead+results[1].Dead)
+}
+
+func TestAlliesDoNotBlockRivals(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{},
+		{0},
+	})
+	require.NoError(t, err)
+	// The allies fill their city up to its capacity, the capacity only limits the placement
+	simulation, err := NewSimulation(testWorld, 3, 10,
+		WithPinnedAliens([]PlacementEntry{
+			{City: "testCity_0", Faction: "red"},
+			{City: "testCity_0", Faction: "red"},
+			{City: "testCity_1", Faction: "blue"},
+		}),
+		WithFightRule(RivalFactions{}))
+	require.NoError(t, err)
+	require.NoError(t, simulation.InitAliens(cities, 3))
+
+	simulation.moveAliens()
+	require.Nil(t, testWorld.GetCity("testCity_0"))
+}
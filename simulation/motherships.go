@@ -0,0 +1,202 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidMothership = errors.New("invalid mothership")
+
+const (
+	// AttributeMothership flags an alien base in the map, e.g. @mothership or @mothership=red
+	AttributeMothership = "mothership"
+	// DefaultLaunchInterval is the number of iterations between two launches of a mothership
+	DefaultLaunchInterval = 10
+	// DefaultAssault is the number of rival aliens needed to destroy a mothership
+	DefaultAssault = 3
+)
+
+// Mothership is an alien base launching aliens of its faction
+type Mothership struct {
+	City    string
+	Faction string
+}
+
+// MothershipRule sets how often the motherships launch aliens and how they can be destroyed.
+// Every rival alien entering a mothership assaults it and dies, the mothership falls to the
+// Assault-th one. Strike is the probability that humans destroy a mothership at each iteration.
+type MothershipRule struct {
+	Interval int
+	Assault  int
+	Strike   float64
+}
+
+// DefaultMothershipRule is used unless the simulation is configured otherwise
+var DefaultMothershipRule = MothershipRule{Interval: DefaultLaunchInterval, Assault: DefaultAssault}
+
+// Validate checks that the rule is valid
+func (r MothershipRule) Validate() error {
+	if r.Interval <= 0 || r.Assault <= 0 || r.Strike < 0 || r.Strike > 1 {
+		return ErrInvalidMothership
+	}
+
+	return nil
+}
+
+// mothership is a standing alien base along with the assaults it suffered
+type mothership struct {
+	city    *types.City
+	faction string
+	hits    int
+}
+
+// ParseMotherships parses the motherships separated by ',', each one optionally
+// followed by its faction, e.g. "Berlin:red,Paris".
+func ParseMotherships(spec string) ([]Mothership, error) {
+	motherships := make([]Mothership, 0)
+
+	for _, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) > 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMothership, entry)
+		}
+
+		mothership := Mothership{City: parts[0]}
+		if len(parts) == 2 {
+			mothership.Faction = parts[1]
+		}
+
+		motherships = append(motherships, mothership)
+	}
+
+	return motherships, nil
+}
+
+// MapMotherships returns the cities flagged with the @mothership attribute, a
+// string value is the faction of the mothership.
+func MapMotherships(cities []*types.City) []Mothership {
+	motherships := make([]Mothership, 0)
+
+	for _, city := range cities {
+		attribute, ok := city.Attribute(AttributeMothership)
+		if !ok || (attribute.Kind == types.BoolAttribute && !attribute.Bool) {
+			continue
+		}
+
+		mothership := Mothership{City: city.Name}
+		if attribute.Kind == types.StringAttribute {
+			mothership.Faction = attribute.Raw
+		}
+
+		motherships = append(motherships, mothership)
+	}
+
+	return motherships
+}
+
+// findMothership returns the standing mothership in the city, nil if there is none
+func (s *Simulation) findMothership(city *types.City) *mothership {
+	for _, base := range s.motherships {
+		if base.city == city {
+			return base
+		}
+	}
+
+	return nil
+}
+
+// removeMothership forgets the mothership in the destroyed city
+func (s *Simulation) removeMothership(city *types.City) {
+	for i, base := range s.motherships {
+		if base.city == city {
+			s.motherships = append(s.motherships[:i], s.motherships[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// launchAliens lets every mothership launch an alien into a random neighbour
+// with room left, the launched alien fights the rivals it lands with.
+func (s *Simulation) launchAliens() {
+	if s.count == 0 || s.count%s.mothershipRule.Interval != 0 {
+		return
+	}
+
+	for _, base := range append([]*mothership(nil), s.motherships...) {
+		if !s.standing(base.city) {
+			continue
+		}
+
+		cities := make([]*types.City, 0, len(base.city.Neighbours))
+		free := make([]int, 0, len(base.city.Neighbours))
+		weights := make([]float64, 0, len(base.city.Neighbours))
+
+		for _, link := range base.city.Links() {
+			if link.Road.Blocked {
+				continue
+			}
+
+			cities = append(cities, link.To)
+			free = append(free, s.capacity-len(link.To.OccupiedAliens))
+			weights = append(weights, float64(base.city.RoadWeight(link.Direction)))
+		}
+
+		i := pickWeighted(weights, free, s.rng)
+		if i < 0 {
+			s.emit(Event{Kind: EventLaunchBlocked, City: base.city.Name})
+
+			continue
+		}
+
+		alien := s.newAlien(len(s.roster), cities[i])
+		alien.Faction = base.faction
+		s.addAlien(alien)
+		s.emit(Event{Kind: EventLaunch, City: base.city.Name, To: cities[i].Name, Aliens: []string{alien.String()}})
+
+		if s.shouldFight(cities[i].OccupiedAliens) {
+			s.resolveFight(cities[i])
+		}
+	}
+}
+
+// assaultMothership lets the rival alien entering a mothership assault it, the
+// alien dies unless its assault destroys the mothership along with every alien inside.
+func (s *Simulation) assaultMothership(city *types.City, alien *types.Alien) bool {
+	base := s.findMothership(city)
+	if base == nil || alien.Faction == base.faction {
+		return false
+	}
+
+	base.hits++
+	s.emit(Event{Kind: EventAssault, City: city.Name, Aliens: []string{alien.String()}, Count: base.hits})
+
+	if base.hits >= s.mothershipRule.Assault {
+		s.distroyCity(city)
+	} else {
+		s.killAliens([]*types.Alien{alien})
+	}
+
+	return true
+}
+
+// strikeMotherships gives the humans a chance to destroy every standing mothership
+func (s *Simulation) strikeMotherships() {
+	if s.mothershipRule.Strike == 0 {
+		return
+	}
+
+	for _, base := range append([]*mothership(nil), s.motherships...) {
+		if s.standing(base.city) && s.rng.Float64() < s.mothershipRule.Strike {
+			s.emit(Event{Kind: EventHumanStrike, City: base.city.Name})
+			s.distroyCity(base.city)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMotherships(t *testing.T) {
+	motherships, err := ParseMotherships("Berlin:red, Paris")
+	require.NoError(t, err)
+	require.Equal(t, []Mothership{{City: "Berlin", Faction: "red"}, {City: "Paris"}}, motherships)
+
+	_, err = ParseMotherships("Berlin:red:blue")
+	require.ErrorIs(t, err, ErrInvalidMothership)
+
+	_, cities := createTestWorld(3)
+	cities[0].SetAttribute(AttributeMothership, "true")
+	cities[1].SetAttribute(AttributeMothership, "red")
+	cities[2].SetAttribute(AttributeMothership, "false")
+	require.Equal(t, []Mothership{{City: "testCity_0"}, {City: "testCity_1", Faction: "red"}}, MapMotherships(cities))
+}
+
+func TestMothershipLaunches(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard), WithCapacity(1),
+		WithMotherships([]Mothership{{City: "testCity_0", Faction: "red"}}, MothershipRule{Interval: 2, Assault: 1}))
+	require.NoError(t, err)
+
+	simulation.count = 1
+	simulation.launchAliens()
+	require.Empty(t, simulation.aliens)
+
+	simulation.count = 2
+	simulation.launchAliens()
+	require.Len(t, cities[1].OccupiedAliens, 1)
+	require.Equal(t, "red", simulation.aliens.GetAlien(0).Faction)
+	// The only neighbour is full
+	simulation.count = 4
+	simulation.launchAliens()
+	require.Len(t, simulation.aliens, 1)
+
+	kinds := make([]EventKind, 0)
+	for _, event := range simulation.Events() {
+		kinds = append(kinds, event.Kind)
+	}
+
+	require.Equal(t, []EventKind{EventLaunch, EventLaunchBlocked}, kinds)
+	require.True(t, simulation.CanContinue())
+}
+
+func TestMothershipAssault(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 2, 10, WithOutput(ioutil.Discard), WithFightRule(RivalFactions{}),
+		WithMotherships([]Mothership{{City: "testCity_0", Faction: "red"}}, MothershipRule{Interval: 5, Assault: 2}))
+	require.NoError(t, err)
+
+	for id := 0; id < 2; id++ {
+		alien := types.NewAlien(id, "", cities[1])
+		alien.Faction = "blue"
+		simulation.addAlien(alien)
+	}
+
+	simulation.arrive(simulation.aliens.GetAlien(0), cities[0])
+	require.Len(t, simulation.aliens, 1)
+	require.Equal(t, cities[0], testWorld.GetCity("testCity_0"))
+
+	simulation.arrive(simulation.aliens.GetAlien(1), cities[0])
+	require.Empty(t, simulation.aliens)
+	require.Nil(t, testWorld.GetCity("testCity_0"))
+	require.Empty(t, simulation.motherships)
+
+	kinds := make([]EventKind, 0)
+	for _, event := range simulation.Events() {
+		kinds = append(kinds, event.Kind)
+	}
+
+	require.Equal(t, []EventKind{EventAssault, EventAssault, EventCityDestroyed}, kinds)
+}
+
+func TestMothershipSurvivesBattles(t *testing.T) {
+	testWorld, cities := createTestWorld(1)
+
+	simulation, err := NewSimulation(testWorld, 2, 10, WithOutput(ioutil.Discard),
+		WithMotherships([]Mothership{{City: "testCity_0"}}, DefaultMothershipRule))
+	require.NoError(t, err)
+
+	simulation.addAlien(types.NewAlien(0, "", cities[0]))
+	simulation.addAlien(types.NewAlien(1, "", cities[0]))
+	simulation.checkForFight()
+	require.Empty(t, simulation.aliens)
+	require.NotNil(t, testWorld.GetCity("testCity_0"))
+
+	simulation, err = NewSimulation(testWorld, 2, 10, WithOutput(ioutil.Discard),
+		WithMotherships([]Mothership{{City: "testCity_0"}}, MothershipRule{Interval: 1, Assault: 1, Strike: 1}))
+	require.NoError(t, err)
+
+	simulation.strikeMotherships()
+	require.Nil(t, testWorld.GetCity("testCity_0"))
+
+	testWorld, _ = createTestWorld(1)
+	_, err = NewSimulation(testWorld, 2, 10, WithMotherships([]Mothership{{City: "nowhere"}}, DefaultMothershipRule))
+	require.ErrorIs(t, err, ErrUnknownCity)
+}
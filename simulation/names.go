@@ -0,0 +1,56 @@
+package simulation
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNoAlienNames = errors.New("no alien names")
+
+var (
+	namePrefixes = []string{"Zor", "Bli", "Kra", "Vex", "Glo", "Xan", "Qua", "Mor", "Tri", "Zel"}
+	nameSuffixes = []string{"g", "p", "nax", "bo", "tor", "lix", "um", "ra", "ek", "os"}
+)
+
+// LoadAlienNames reads one alien name per line from the given file
+func LoadAlienNames(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading file")
+	}
+	defer file.Close()
+
+	names := make([]string, 0)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); len(name) > 0 {
+			names = append(names, name)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, ErrNoAlienNames
+	}
+
+	return names, nil
+}
+
+// GenerateAlienName returns a deterministic and unique name for the given alien Id
+func GenerateAlienName(id int) string {
+	name := namePrefixes[id%len(namePrefixes)] + nameSuffixes[(id/len(namePrefixes))%len(nameSuffixes)]
+
+	if round := id / (len(namePrefixes) * len(nameSuffixes)); round > 0 {
+		name += "-" + strconv.Itoa(round+1)
+	}
+
+	return name
+}
@@ -0,0 +1,37 @@
+package simulation
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAlienNames(t *testing.T) {
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte("Zorg\n\n  Blip  \n"), 0600)
+	require.NoError(t, err)
+
+	names, err := LoadAlienNames(fileName)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Zorg", "Blip"}, names)
+
+	_, emptyFile := createTempFile(t)
+	_, err = LoadAlienNames(emptyFile)
+	require.ErrorIs(t, err, ErrNoAlienNames)
+}
+
+func TestGenerateAlienName(t *testing.T) {
+	seen := make(map[string]int)
+
+	for id := 0; id < 1000; id++ {
+		name := GenerateAlienName(id)
+		require.Equal(t, name, GenerateAlienName(id), "Generated names are expected to be deterministic")
+
+		if other, ok := seen[name]; ok {
+			t.Fatalf("alien %d and alien %d share the name %s", other, id, name)
+		}
+
+		seen[name] = id
+	}
+}
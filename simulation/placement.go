@@ -0,0 +1,307 @@
+package simulation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+const (
+	PlacementUniform   = "uniform"
+	PlacementClustered = "clustered"
+	PlacementDegree    = "degree"
+	PlacementEdge      = "edge"
+	// PlacementAttribute is followed by the attribute key, e.g. attribute:population
+	PlacementAttribute = "attribute:"
+)
+
+var (
+	ErrUnknownPlacement = errors.New("unknown placement strategy")
+	ErrPlacementFailed  = errors.New("not enough free cities to place aliens")
+	ErrInvalidPlacement = errors.New("invalid placement entry")
+	ErrUnknownCity      = errors.New("unknown city")
+)
+
+// PlacementEntry pins an alien to a city, optionally within a faction
+type PlacementEntry struct {
+	City    string
+	Faction string
+}
+
+// LoadPlacementFile reads one "<city> [faction]" entry per line, quoted as in the
+// map files, the n-th entry is used for the alien with Id n.
+func LoadPlacementFile(filePath string) ([]PlacementEntry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]PlacementEntry, 0)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		tokens, err := tokenize(scanner.Text())
+		if err != nil || len(tokens) > 2 || (len(tokens) > 0 && tokens[0].hasValue) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPlacement, scanner.Text())
+		}
+
+		switch len(tokens) {
+		case 0:
+			continue
+		case 1:
+			entries = append(entries, PlacementEntry{City: tokens[0].key})
+		case 2:
+			entries = append(entries, PlacementEntry{City: tokens[0].key, Faction: tokens[1].key})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// PlacementStrategy selects the cities eligible for the initial alien allocation
+type PlacementStrategy interface {
+	// Candidates returns the eligible cities along with their relative weights
+	Candidates(cities []*types.City, rng *rand.Rand) ([]*types.City, []float64)
+}
+
+// ParsePlacement returns the placement strategy registered under the given name
+func ParsePlacement(name string, clusters int, directions types.DirectionSet) (PlacementStrategy, error) {
+	switch strings.ToLower(name) {
+	case "", PlacementUniform:
+		return Uniform{}, nil
+	case PlacementClustered:
+		if clusters <= 0 {
+			return nil, errors.New("invalid clusters count")
+		}
+
+		return Clustered{Seeds: clusters}, nil
+	case PlacementDegree:
+		return Degree{}, nil
+	case PlacementEdge:
+		return Edge{Directions: directions}, nil
+	}
+
+	if key := strings.TrimPrefix(name, PlacementAttribute); key != name && key != "" {
+		return Attribute{Key: key}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownPlacement, name)
+}
+
+// Uniform gives every city the same chance of being picked
+type Uniform struct{}
+
+func (Uniform) Candidates(cities []*types.City, _ *rand.Rand) ([]*types.City, []float64) {
+	weights := make([]float64, len(cities))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	return cities, weights
+}
+
+// Clustered picks Seeds random cities and favours the cities close to them,
+// the weight halves with every hop away from the nearest seed.
+type Clustered struct {
+	Seeds int
+}
+
+func (c Clustered) Candidates(cities []*types.City, rng *rand.Rand) ([]*types.City, []float64) {
+	distance := make(map[*types.City]int, len(cities))
+	queue := make([]*types.City, 0, len(cities))
+
+	for _, i := range rng.Perm(len(cities)) {
+		if len(queue) == c.Seeds {
+			break
+		}
+
+		distance[cities[i]] = 0
+		queue = append(queue, cities[i])
+	}
+	// Breadth first walk from all the seeds at once
+	for len(queue) > 0 {
+		city := queue[0]
+		queue = queue[1:]
+
+		for _, neighbour := range city.Neighbours {
+			if neighbour == nil {
+				continue
+			}
+
+			if _, ok := distance[neighbour]; !ok {
+				distance[neighbour] = distance[city] + 1
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+
+	candidates := make([]*types.City, 0, len(distance))
+	weights := make([]float64, 0, len(distance))
+
+	for _, city := range cities {
+		if d, ok := distance[city]; ok {
+			candidates = append(candidates, city)
+			weights = append(weights, math.Pow(0.5, float64(d)))
+		}
+	}
+
+	return candidates, weights
+}
+
+// Degree favours well connected cities, the weight is the total weight of
+// the roads leading in or out of the city.
+type Degree struct{}
+
+func (Degree) Candidates(cities []*types.City, _ *rand.Rand) ([]*types.City, []float64) {
+	degree := make(map[*types.City]int, len(cities))
+
+	for _, city := range cities {
+		for direction, neighbour := range city.Neighbours {
+			if neighbour != nil {
+				degree[city] += city.RoadWeight(direction)
+				degree[neighbour] += city.RoadWeight(direction)
+			}
+		}
+	}
+
+	candidates := make([]*types.City, 0, len(cities))
+	weights := make([]float64, 0, len(cities))
+
+	for _, city := range cities {
+		if degree[city] > 0 {
+			candidates = append(candidates, city)
+			weights = append(weights, float64(degree[city]))
+		}
+	}
+
+	return candidates, weights
+}
+
+// Edge only places aliens on the border of the map, i.e. the cities missing
+// a neighbour in at least one direction of the set, the 4-way compass by default.
+type Edge struct {
+	Directions types.DirectionSet
+}
+
+func (e Edge) Candidates(cities []*types.City, _ *rand.Rand) ([]*types.City, []float64) {
+	candidates := make([]*types.City, 0, len(cities))
+	weights := make([]float64, 0, len(cities))
+
+	directions := e.Directions
+	if directions.Name == "" {
+		directions = types.FourWay
+	}
+
+	for _, city := range cities {
+		if countNeighbours(city) < len(directions.Directions) {
+			candidates = append(candidates, city)
+			weights = append(weights, 1)
+		}
+	}
+
+	return candidates, weights
+}
+
+// Attribute weights the cities by a numeric city attribute, the cities
+// without a positive value are not eligible.
+type Attribute struct {
+	Key string
+}
+
+func (a Attribute) Candidates(cities []*types.City, _ *rand.Rand) ([]*types.City, []float64) {
+	candidates := make([]*types.City, 0, len(cities))
+	weights := make([]float64, 0, len(cities))
+
+	for _, city := range cities {
+		if weight, ok := city.FloatAttribute(a.Key); ok && weight > 0 {
+			candidates = append(candidates, city)
+			weights = append(weights, weight)
+		}
+	}
+
+	return candidates, weights
+}
+
+// placeAliens picks a city for each alien following the strategy weights,
+// cities are dropped from the draw as soon as they are full.
+func placeAliens(strategy PlacementStrategy, cities []*types.City, aliensCount, capacity int,
+	rng *rand.Rand) ([]*types.City, error) {
+	candidates, weights := strategy.Candidates(cities, rng)
+	// Copy the slices, they are mutated while drawing
+	candidates = append([]*types.City(nil), candidates...)
+	weights = append([]float64(nil), weights...)
+
+	free := make([]int, len(candidates))
+	for i, city := range candidates {
+		free[i] = capacity - len(city.OccupiedAliens)
+	}
+
+	placed := make([]*types.City, 0, aliensCount)
+
+	for len(placed) < aliensCount {
+		i := pickWeighted(weights, free, rng)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: placed %d of %d aliens", ErrPlacementFailed, len(placed), aliensCount)
+		}
+
+		placed = append(placed, candidates[i])
+		free[i]--
+	}
+
+	return placed, nil
+}
+
+// pickWeighted returns the index of a random entry with free slots, -1 if there is none
+func pickWeighted(weights []float64, free []int, rng *rand.Rand) int {
+	total := 0.0
+
+	for i, w := range weights {
+		if free[i] > 0 && w > 0 {
+			total += w
+		}
+	}
+
+	if total <= 0 {
+		return -1
+	}
+
+	target := rng.Float64() * total
+	last := -1
+
+	for i, w := range weights {
+		if free[i] <= 0 || w <= 0 {
+			continue
+		}
+
+		last = i
+		if target -= w; target < 0 {
+			return i
+		}
+	}
+	// Floating point rounding, fallback to the last eligible entry
+	return last
+}
+
+// countNeighbours returns the number of non nil neighbours of the city
+func countNeighbours(city *types.City) int {
+	count := 0
+
+	for _, neighbour := range city.Neighbours {
+		if neighbour != nil {
+			count++
+		}
+	}
+
+	return count
+}
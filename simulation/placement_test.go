@@ -0,0 +1,165 @@
+package simulation
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlacement(t *testing.T) {
+	tests := []struct {
+		name       string
+		placement  string
+		clusters   int
+		shouldFail bool
+		expected   PlacementStrategy
+	}{
+		{name: "Default", placement: "", expected: Uniform{}},
+		{name: "Uniform", placement: "uniform", expected: Uniform{}},
+		{name: "Clustered", placement: "clustered", clusters: 3, expected: Clustered{Seeds: 3}},
+		{name: "Clustered without seeds", placement: "clustered", shouldFail: true},
+		{name: "Degree", placement: "Degree", expected: Degree{}},
+		{name: "Edge", placement: "edge", expected: Edge{Directions: types.FourWay}},
+		{name: "Attribute", placement: "attribute:population", expected: Attribute{Key: "population"}},
+		{name: "Attribute without key", placement: "attribute:", shouldFail: true},
+		{name: "Unknown", placement: "spiral", shouldFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := ParsePlacement(tt.placement, tt.clusters, types.FourWay)
+			if tt.shouldFail {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, strategy)
+			}
+		})
+	}
+}
+
+func TestPlacementRespectsCapacity(t *testing.T) {
+	strategies := []PlacementStrategy{Uniform{}, Clustered{Seeds: 2}, Degree{}, Edge{}}
+
+	for _, strategy := range strategies {
+		// Line of cities, every city is on the edge and has a road
+		_, cities, err := createTestWorldWithNeighbours(5, [][]int{
+			{1}, {2}, {3}, {4}, {0},
+		})
+		require.NoError(t, err)
+
+		rng := rand.New(rand.NewSource(1)) //nolint:gosec
+		placed, err := placeAliens(strategy, cities, 15, 3, rng)
+		require.NoError(t, err)
+		require.Len(t, placed, 15)
+
+		occupancy := make(map[*types.City]int)
+		for _, city := range placed {
+			occupancy[city]++
+		}
+
+		for _, count := range occupancy {
+			require.LessOrEqual(t, count, 3, "Max occupancy per city is expected to be <=3")
+		}
+	}
+}
+
+func TestPlacementEdgeOnly(t *testing.T) {
+	_, cities, err := createTestWorldWithNeighbours(5, [][]int{
+		{1, 2, 3, 4},
+		{0},
+		{0},
+		{0},
+		{0},
+	})
+	require.NoError(t, err)
+
+	candidates, _ := Edge{}.Candidates(cities, rand.New(rand.NewSource(1))) //nolint:gosec
+	require.NotContains(t, candidates, cities[0], "Fully connected city is not on the edge")
+	require.Len(t, candidates, 4)
+}
+
+func TestPlacementClusteredStaysConnected(t *testing.T) {
+	// Two disconnected components, a single seed only reaches one of them
+	_, cities, err := createTestWorldWithNeighbours(4, [][]int{
+		{1}, {0}, {3}, {2},
+	})
+	require.NoError(t, err)
+
+	placed, err := placeAliens(Clustered{Seeds: 1}, cities, 4, 2, rand.New(rand.NewSource(1))) //nolint:gosec
+	require.NoError(t, err)
+
+	component := map[*types.City]bool{placed[0]: true, placed[0].Neighbours[types.North]: true}
+	for _, city := range placed {
+		require.True(t, component[city], "Alien placed outside of the seed cluster")
+	}
+}
+
+func TestInitAliens_PlacementFailed(t *testing.T) {
+	// Every city is fully connected, the edge strategy has no candidates
+	testWorld, cities, err := createTestWorldWithNeighbours(5, [][]int{
+		{1, 2, 3, 4},
+		{0, 2, 3, 4},
+		{0, 1, 3, 4},
+		{0, 1, 2, 4},
+		{0, 1, 2, 3},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 2, 0, WithPlacement(Edge{}))
+	require.NoError(t, err)
+
+	err = simulation.InitAliens(cities, 2)
+	require.ErrorIs(t, err, ErrPlacementFailed)
+}
+
+func TestInitAliens_Capacity(t *testing.T) {
+	testWorld, cities := createTestWorld(10)
+
+	_, err := NewSimulation(testWorld, 10, 0, WithCapacity(0))
+	require.ErrorIs(t, err, ErrInvalidCapacity)
+
+	simulation, err := NewSimulation(testWorld, 30, 0, WithCapacity(3))
+	require.NoError(t, err)
+	require.NoError(t, simulation.InitAliens(cities, 30))
+	require.ErrorIs(t, simulation.InitAliens(cities, 31), ErrInvalidAliensCount)
+}
+
+func TestLoadPlacementFile(t *testing.T) {
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte("Paris red\n\nBerlin\n"), 0600)
+	require.NoError(t, err)
+
+	entries, err := LoadPlacementFile(fileName)
+	require.NoError(t, err)
+	require.Equal(t, []PlacementEntry{{City: "Paris", Faction: "red"}, {City: "Berlin"}}, entries)
+
+	err = os.WriteFile(fileName, []byte("Paris red extra\n"), 0600)
+	require.NoError(t, err)
+
+	_, err = LoadPlacementFile(fileName)
+	require.ErrorIs(t, err, ErrInvalidPlacement)
+}
+
+func TestInitAliens_PinnedUnknownCity(t *testing.T) {
+	testWorld, cities := createTestWorld(2)
+
+	simulation, err := NewSimulation(testWorld, 1, 0, WithPinnedAliens([]PlacementEntry{{City: "Atlantis"}}))
+	require.NoError(t, err)
+	require.ErrorIs(t, simulation.InitAliens(cities, 1), ErrUnknownCity)
+}
+
+func TestPlacementAttributeWeighted(t *testing.T) {
+	_, cities := createTestWorld(3)
+	cities[0].SetAttribute("population", "100")
+	cities[1].SetAttribute("population", "2.5")
+	cities[2].SetAttribute("population", "unknown")
+
+	candidates, weights := Attribute{Key: "population"}.Candidates(cities, nil)
+	require.Equal(t, []*types.City{cities[0], cities[1]}, candidates)
+	require.Equal(t, []float64{100, 2.5}, weights)
+}
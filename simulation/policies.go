@@ -0,0 +1,266 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrUnknownPolicy = errors.New("unknown policy")
+
+const (
+	PolicyQuarantine = "quarantine"
+	PolicyEvacuate   = "evacuate"
+	PolicySacrifice  = "sacrifice"
+	// DefaultEvacuationRate is the share of the population leaving a city hosting aliens
+	DefaultEvacuationRate = 0.5
+	// DefaultSacrificeThreshold is the number of aliens a city is sacrificed for
+	DefaultSacrificeThreshold = 2
+)
+
+// Policy is a human response applied once per iteration, before the aliens move
+type Policy interface {
+	Apply(s *Simulation)
+}
+
+// Quarantine closes every road into the cities adjacent to a city destroyed in the previous iteration
+type Quarantine struct{}
+
+// Evacuate moves a share of the population of the cities hosting aliens toward their safe neighbours
+type Evacuate struct {
+	Rate float64
+}
+
+// Sacrifice destroys the cities hosting at least Threshold aliens, killing the aliens inside
+type Sacrifice struct {
+	Threshold int
+}
+
+// HumanResult is the outcome of the invasion for the humans
+type HumanResult struct {
+	Population  int
+	Surviving   int
+	Evacuated   int
+	Quarantined []string
+	Sacrificed  []string
+}
+
+// fallenCity records a destroyed city along with its neighbours at the time
+type fallenCity struct {
+	city       *types.City
+	neighbours []*types.City
+}
+
+// ParsePolicies parses the policies separated by ',', applied in order, each one
+// optionally followed by its parameter, e.g. "quarantine,evacuate:0.3,sacrifice:3".
+func ParsePolicies(spec string) ([]Policy, error) {
+	policies := make([]Policy, 0)
+
+	for _, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownPolicy, entry)
+		}
+
+		param := ""
+		if len(parts) == 2 {
+			param = parts[1]
+		}
+
+		policy, err := parsePolicy(strings.ToLower(parts[0]), param)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, entry)
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// parsePolicy returns the policy registered under the given name
+func parsePolicy(name, param string) (Policy, error) {
+	switch name {
+	case PolicyQuarantine:
+		if param != "" {
+			return nil, ErrUnknownPolicy
+		}
+
+		return Quarantine{}, nil
+	case PolicyEvacuate:
+		policy := Evacuate{Rate: DefaultEvacuationRate}
+		if param == "" {
+			return policy, nil
+		}
+
+		rate, err := strconv.ParseFloat(param, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, ErrUnknownPolicy
+		}
+
+		policy.Rate = rate
+
+		return policy, nil
+	case PolicySacrifice:
+		policy := Sacrifice{Threshold: DefaultSacrificeThreshold}
+		if param == "" {
+			return policy, nil
+		}
+
+		threshold, err := strconv.Atoi(param)
+		if err != nil || threshold <= 0 {
+			return nil, ErrUnknownPolicy
+		}
+
+		policy.Threshold = threshold
+
+		return policy, nil
+	}
+
+	return nil, ErrUnknownPolicy
+}
+
+// Apply implements the Policy interface
+func (Quarantine) Apply(s *Simulation) {
+	for _, fallen := range s.fallen {
+		for _, city := range fallen.neighbours {
+			if s.standing(city) && !s.quarantined[city] {
+				s.QuarantineCity(city.Name) //nolint:errcheck // the city stands
+			}
+		}
+	}
+}
+
+// QuarantineCity closes every road into the city, along with the roads back
+func (s *Simulation) QuarantineCity(name string) error {
+	if err := s.checkStanding(name); err != nil {
+		return err
+	}
+
+	city := s.worldMap.GetCity(name)
+	if s.quarantined == nil {
+		s.quarantined = make(map[*types.City]bool)
+	}
+
+	if !s.quarantined[city] {
+		s.quarantined[city] = true
+		s.humans.Quarantined = append(s.humans.Quarantined, city.Name)
+	}
+
+	s.emit(Event{Kind: EventQuarantine, City: city.Name})
+
+	for _, road := range city.InboundRoads() {
+		if !road.From.RoadBlocked(road.Direction) {
+			s.blockRoad(road.From, city, true)
+		}
+	}
+
+	return nil
+}
+
+// Apply implements the Policy interface
+func (p Evacuate) Apply(s *Simulation) {
+	for _, name := range s.worldMap.Names() {
+		city := s.worldMap.GetCity(name)
+		if !s.standing(city) || len(city.OccupiedAliens) == 0 || city.Population() == 0 {
+			continue
+		}
+
+		safe := make([]*types.City, 0, len(city.Neighbours))
+
+		for _, link := range city.Links() {
+			if !link.Road.Blocked && len(link.To.OccupiedAliens) == 0 {
+				safe = append(safe, link.To)
+			}
+		}
+
+		if len(safe) == 0 {
+			continue
+		}
+
+		share := int(float64(city.Population())*p.Rate) / len(safe)
+
+		for _, neighbour := range safe {
+			if share == 0 {
+				break
+			}
+
+			city.SetPopulation(city.Population() - share)
+			neighbour.SetPopulation(neighbour.Population() + share)
+			s.humans.Evacuated += share
+			s.emit(Event{Kind: EventEvacuation, From: city.Name, To: neighbour.Name, Count: share})
+		}
+	}
+}
+
+// Apply implements the Policy interface
+func (p Sacrifice) Apply(s *Simulation) {
+	for _, name := range s.worldMap.Names() {
+		city := s.worldMap.GetCity(name)
+		if !s.standing(city) || len(city.OccupiedAliens) < p.Threshold || s.findMothership(city) != nil {
+			continue
+		}
+
+		aliens := s.killAliens(sortedAliens(city.OccupiedAliens))
+		s.humans.Sacrificed = append(s.humans.Sacrificed, city.Name)
+		s.emit(Event{Kind: EventSacrifice, City: city.Name, Aliens: alienNames(aliens)})
+		s.distroyCity(city)
+	}
+}
+
+// applyPolicies runs the human policies, the cities destroyed since the previous
+// run are then forgotten, except the ones destroyed by the policies themselves.
+func (s *Simulation) applyPolicies() {
+	seen := len(s.fallen)
+
+	for _, policy := range s.policies {
+		policy.Apply(s)
+	}
+
+	s.fallen = append([]fallenCity(nil), s.fallen[seen:]...)
+}
+
+// neighbourhood returns the cities linked to the city by a road, in either direction
+func neighbourhood(city *types.City) []*types.City {
+	seen := make(map[*types.City]bool)
+	cities := make([]*types.City, 0, len(city.Neighbours)+len(city.Inbound))
+
+	for _, link := range city.Links() {
+		if !seen[link.To] {
+			seen[link.To] = true
+			cities = append(cities, link.To)
+		}
+	}
+
+	for _, road := range city.InboundRoads() {
+		if !seen[road.From] {
+			seen[road.From] = true
+			cities = append(cities, road.From)
+		}
+	}
+
+	return cities
+}
+
+// HumanResult returns the outcome of the invasion for the humans
+func (s *Simulation) HumanResult() HumanResult {
+	result := s.humans
+	result.Population = s.population
+	result.Quarantined = append([]string(nil), s.humans.Quarantined...)
+	result.Sacrificed = append([]string(nil), s.humans.Sacrificed...)
+
+	for _, name := range s.worldMap.Names() {
+		if city := s.worldMap.GetCity(name); s.standing(city) {
+			result.Surviving += city.Population()
+		}
+	}
+
+	return result
+}
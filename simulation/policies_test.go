@@ -0,0 +1,101 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("quarantine, Evacuate:0.3,sacrifice:3,evacuate")
+	require.NoError(t, err)
+	require.Equal(t, []Policy{Quarantine{}, Evacuate{Rate: 0.3}, Sacrifice{Threshold: 3},
+		Evacuate{Rate: DefaultEvacuationRate}}, policies)
+
+	for _, spec := range []string{"pray", "quarantine:1", "evacuate:2", "sacrifice:0", "sacrifice:1:2"} {
+		_, err = ParsePolicies(spec)
+		require.ErrorIs(t, err, ErrUnknownPolicy, spec)
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(4, [][]int{
+		{1},
+		{0, 2},
+		{1, 3},
+		{2},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard),
+		WithPolicies([]Policy{Quarantine{}}))
+	require.NoError(t, err)
+
+	simulation.distroyCity(cities[0])
+	simulation.applyPolicies()
+	// The neighbour of the destroyed city is cut off, the other roads stay open
+	require.True(t, cities[2].RoadBlocked(types.North))
+	require.True(t, cities[1].RoadBlocked(types.South))
+	require.False(t, cities[3].RoadBlocked(types.North))
+	require.Equal(t, []string{"testCity_1"}, simulation.HumanResult().Quarantined)
+
+	// The destroyed city is forgotten once the policies ran
+	simulation.applyPolicies()
+	require.Equal(t, []string{"testCity_1"}, simulation.HumanResult().Quarantined)
+}
+
+func TestEvacuate(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(3, [][]int{
+		{1, 2},
+		{0},
+		{0},
+	})
+	require.NoError(t, err)
+	cities[0].SetPopulation(1000)
+	cities[2].SetPopulation(10)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard),
+		WithPolicies([]Policy{Evacuate{Rate: 0.5}}))
+	require.NoError(t, err)
+
+	simulation.addAlien(types.NewAlien(0, "", cities[0]))
+	simulation.addAlien(types.NewAlien(1, "", cities[2]))
+	simulation.applyPolicies()
+	// Only the neighbour without aliens is safe
+	require.Equal(t, 500, cities[0].Population())
+	require.Equal(t, 500, cities[1].Population())
+	require.Equal(t, 10, cities[2].Population())
+
+	result := simulation.HumanResult()
+	require.Equal(t, 1010, result.Population)
+	require.Equal(t, 1010, result.Surviving)
+	require.Equal(t, 500, result.Evacuated)
+}
+
+func TestSacrifice(t *testing.T) {
+	testWorld, cities := createTestWorld(2)
+	cities[0].SetPopulation(100)
+
+	simulation, err := NewSimulation(testWorld, 3, 10, WithOutput(ioutil.Discard), WithFightRule(RivalFactions{}),
+		WithPolicies([]Policy{Sacrifice{Threshold: 2}}))
+	require.NoError(t, err)
+
+	simulation.addAlien(types.NewAlien(0, "", cities[0]))
+	simulation.addAlien(types.NewAlien(1, "", cities[0]))
+	simulation.addAlien(types.NewAlien(2, "", cities[1]))
+	simulation.applyPolicies()
+
+	require.Equal(t, []int{2}, simulation.aliens.IDs())
+	require.Nil(t, testWorld.GetCity("testCity_0"))
+
+	result := simulation.HumanResult()
+	require.Equal(t, []string{"testCity_0"}, result.Sacrificed)
+	require.Equal(t, 0, result.Surviving)
+	require.Equal(t, 0, simulation.destroyedBy[""])
+
+	events := simulation.Events()
+	require.Equal(t, EventSacrifice, events[0].Kind)
+	require.Equal(t, "testCity_0 has been destroyed ! ", events[1].String())
+}
@@ -0,0 +1,121 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var (
+	ErrInvalidRecovery = errors.New("invalid recovery")
+	ErrCityInRuins     = errors.New("city in ruins")
+)
+
+// ruin is a destroyed city waiting to be rebuilt, along with the roads it had
+type ruin struct {
+	city        *types.City
+	destroyedAt int
+	roads       []roadSnapshot
+}
+
+// roadSnapshot records a road leading in or out of a destroyed city
+type roadSnapshot struct {
+	from      *types.City
+	to        *types.City
+	direction types.Direction
+	road      types.Road
+}
+
+// snapshotRoads records the roads leading in and out of the city
+func snapshotRoads(city *types.City) []roadSnapshot {
+	roads := make([]roadSnapshot, 0, len(city.Neighbours)+len(city.Inbound))
+
+	for _, link := range city.Links() {
+		roads = append(roads, roadSnapshot{from: city, to: link.To, direction: link.Direction, road: link.Road})
+	}
+
+	for _, inbound := range city.InboundRoads() {
+		roads = append(roads, roadSnapshot{from: inbound.From, to: city, direction: inbound.Direction,
+			road: inbound.From.Roads[inbound.Direction]})
+	}
+
+	return roads
+}
+
+// standing checks whether the city is part of the world and not in ruins
+func (s *Simulation) standing(city *types.City) bool {
+	return s.worldMap.GetCity(city.Name) == city && city.State == types.Intact
+}
+
+// rebuildCities rebuilds the ruins left for the recovery period, the roads are
+// restored wherever the neighbours still stand. The roads to neighbours still in
+// ruins are handed over to them, they come back along with the neighbour.
+func (s *Simulation) rebuildCities() {
+	remaining := make([]*ruin, 0, len(s.ruins))
+
+	for _, r := range s.ruins {
+		if s.count-r.destroyedAt < s.recovery {
+			remaining = append(remaining, r)
+
+			continue
+		}
+
+		r.city.State = types.Intact
+		r.city.Damaged = false
+
+		for _, snapshot := range r.roads {
+			other := snapshot.to
+			if other == r.city {
+				other = snapshot.from
+			}
+
+			if pending := s.findRuin(other); pending != nil {
+				pending.roads = append(pending.roads, snapshot)
+
+				continue
+			}
+
+			if !s.standing(snapshot.from) || !s.standing(snapshot.to) || snapshot.from.Neighbours[snapshot.direction] != nil {
+				continue
+			}
+
+			snapshot.from.SetNeighbour(snapshot.direction, snapshot.to)
+			snapshot.from.SetRoadWeight(snapshot.direction, snapshot.road.Weight)
+			snapshot.from.SetRoadLength(snapshot.direction, snapshot.road.Length)
+		}
+
+		s.emit(Event{Kind: EventCityRebuilt, City: r.city.Name})
+	}
+
+	s.ruins = remaining
+}
+
+// findRuin returns the ruin of the city, nil if the city is not in ruins
+func (s *Simulation) findRuin(city *types.City) *ruin {
+	if city.State != types.Ruins {
+		return nil
+	}
+
+	for _, r := range s.ruins {
+		if r.city == city {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// checkStanding returns an error naming the city unless it stands
+func (s *Simulation) checkStanding(name string) error {
+	city := s.worldMap.GetCity(name)
+	if city == nil {
+		return fmt.Errorf("%w: %s", types.ErrCityNotFound, name)
+	}
+
+	if city.State == types.Ruins {
+		return fmt.Errorf("%w: %s", ErrCityInRuins, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(3, [][]int{
+		{1},
+		{0, 2},
+		{1},
+	})
+	require.NoError(t, err)
+	cities[0].SetRoadWeight(types.North, 3)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithRecovery(2), WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+
+	simulation.distroyCity(cities[1])
+	simulation.distroyCity(cities[2])
+	require.Equal(t, types.Ruins, cities[1].State)
+	require.Equal(t, cities[1], testWorld.GetCity("testCity_1"))
+	require.Empty(t, cities[0].Neighbours)
+	require.ErrorIs(t, simulation.AddRoad("testCity_0", types.East, "testCity_1"), ErrCityInRuins)
+
+	simulation.count++
+	simulation.rebuildCities()
+	require.Equal(t, types.Ruins, cities[1].State)
+
+	simulation.count++
+	simulation.rebuildCities()
+	require.Equal(t, types.Intact, cities[1].State)
+	require.Equal(t, types.Intact, cities[2].State)
+	// Both roads come back whatever the order of the rebuilds, along with their weights
+	require.Equal(t, cities[1], cities[0].Neighbours[types.North])
+	require.Equal(t, 3, cities[0].RoadWeight(types.North))
+	require.Equal(t, cities[0], cities[1].Neighbours[types.North])
+	require.Equal(t, cities[2], cities[1].Neighbours[types.South])
+	require.Equal(t, cities[1], cities[2].Neighbours[types.North])
+	require.Empty(t, simulation.ruins)
+
+	kinds := make([]EventKind, 0)
+	for _, event := range simulation.Events() {
+		kinds = append(kinds, event.Kind)
+	}
+
+	require.Equal(t, []EventKind{EventCityDestroyed, EventCityDestroyed, EventCityRebuilt, EventCityRebuilt}, kinds)
+}
+
+func TestRecoveryDisabled(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+
+	simulation.distroyCity(cities[1])
+	require.Nil(t, testWorld.GetCity("testCity_1"))
+	require.Empty(t, simulation.ruins)
+
+	_, err = NewSimulation(testWorld, 1, 10, WithRecovery(-1))
+	require.ErrorIs(t, err, ErrInvalidRecovery)
+}
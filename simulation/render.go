@@ -0,0 +1,194 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidGrid = errors.New("invalid grid")
+
+// gridStep is the move from a grid cell to its neighbour in the given direction
+type gridStep struct {
+	direction  types.Direction
+	dx, dy, dz int
+}
+
+// gridSteps lists, for every direction set, half of its directions, the other half being their opposites.
+// The hex grid uses axial coordinates, a cell is linked to the next column to the southeast and northeast.
+var gridSteps = map[string][]gridStep{
+	types.FourWay.Name: {{types.East, 1, 0, 0}, {types.South, 0, 1, 0}},
+	types.EightWay.Name: {
+		{types.East, 1, 0, 0}, {types.South, 0, 1, 0}, {types.SouthEast, 1, 1, 0}, {types.SouthWest, -1, 1, 0},
+	},
+	types.Hex.Name:    {{types.SouthEast, 1, 0, 0}, {types.South, 0, 1, 0}, {types.NorthEast, 1, -1, 0}},
+	types.ThreeD.Name: {{types.East, 1, 0, 0}, {types.South, 0, 1, 0}, {types.Down, 0, 0, 1}},
+}
+
+// GenerateGrid lays count cities out on a grid of the direction set, square for the compass
+// sets and in stacked square layers for the 3d set. Each road between two adjacent cities is
+// kept with the given probability, in both directions. The cities left without a road are
+// linked to an adjacent city so the map loads.
+func GenerateGrid(count int, roadChance float64, directions types.DirectionSet, rng *rand.Rand) (types.World, error) {
+	steps, ok := gridSteps[directions.Name]
+	if !ok || count < 2 || roadChance < 0 || roadChance > 1 {
+		return nil, ErrInvalidGrid
+	}
+
+	width, layer := int(math.Ceil(math.Sqrt(float64(count)))), count
+	if directions.Name == types.ThreeD.Name {
+		width = int(math.Ceil(math.Cbrt(float64(count))))
+		layer = width * width
+	}
+
+	world := types.NewWorldMap()
+	cities := make([]*types.City, count)
+	digits := len(strconv.Itoa(count))
+
+	for i := range cities {
+		cities[i] = &types.City{
+			Name:       fmt.Sprintf("City%0*d", digits, i+1),
+			Neighbours: make(map[types.Direction]*types.City),
+		}
+		world.AddCity(cities[i]) //nolint:errcheck // the names are unique
+	}
+	// neighbour returns the index of the cell reached by the step, scaled by sign, or -1 out of the grid
+	neighbour := func(i int, step gridStep, sign int) int {
+		x, y, z := i%width+sign*step.dx, i%layer/width+sign*step.dy, i/layer+sign*step.dz
+		if x < 0 || x >= width || y < 0 || z < 0 || (layer != count && y >= width) {
+			return -1
+		}
+
+		if j := z*layer + y*width + x; j < count {
+			return j
+		}
+
+		return -1
+	}
+
+	link := func(from, to int, direction types.Direction) {
+		opposite, _ := directions.Opposite(direction)
+		cities[from].SetNeighbour(direction, cities[to])
+		cities[to].SetNeighbour(opposite, cities[from])
+	}
+
+	for i := range cities {
+		for _, step := range steps {
+			if j := neighbour(i, step, 1); j >= 0 && rng.Float64() < roadChance {
+				link(i, j, step.direction)
+			}
+		}
+	}
+
+	for i, city := range cities {
+		if len(city.Neighbours) > 0 {
+			continue
+		}
+
+	search:
+		for _, step := range steps {
+			for _, sign := range []int{-1, 1} {
+				if j := neighbour(i, step, sign); j >= 0 {
+					if sign < 0 {
+						link(j, i, step.direction)
+					} else {
+						link(i, j, step.direction)
+					}
+
+					break search
+				}
+			}
+		}
+	}
+
+	return world, nil
+}
+
+// PrintWorld prints the standing cities in the map format, preceded by a @directions
+// header when the roads use another direction set than the 4-way compass
+func PrintWorld(w io.Writer, worldMap types.World, directions types.DirectionSet) {
+	if directions.Name != types.FourWay.Name {
+		fmt.Fprintf(w, "%s %s\n", directiveDirections, directions.Name)
+	}
+
+	for _, name := range worldMap.Names() {
+		if city := worldMap.GetCity(name); city.State != types.Ruins {
+			fmt.Fprintln(w, city.String())
+		}
+	}
+}
+
+// PrintDOT prints the standing cities as a Graphviz digraph along with their attributes,
+// the roads are labelled with their direction and carry their weight and length
+func PrintDOT(w io.Writer, worldMap types.World) {
+	fmt.Fprintln(w, "digraph world {")
+
+	for _, name := range worldMap.Names() {
+		city := worldMap.GetCity(name)
+		if city.State == types.Ruins {
+			continue
+		}
+
+		attributes := make([]string, 0, len(city.Attributes))
+		for _, key := range city.AttributeKeys() {
+			attributes = append(attributes, fmt.Sprintf("%s=%s", strconv.Quote(key), strconv.Quote(city.Attributes[key].String())))
+		}
+
+		if len(attributes) > 0 {
+			fmt.Fprintf(w, "  %s [%s];\n", strconv.Quote(city.Name), strings.Join(attributes, ", "))
+		} else {
+			fmt.Fprintf(w, "  %s;\n", strconv.Quote(city.Name))
+		}
+
+		for _, link := range city.Links() {
+			fmt.Fprintf(w, "  %s -> %s [label=%s, weight=%d, length=%d];\n", strconv.Quote(city.Name),
+				strconv.Quote(link.To.Name), strconv.Quote(types.GetDirection(link.Direction)),
+				city.RoadWeight(link.Direction), city.RoadLength(link.Direction))
+		}
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+// Regions splits the cities into the groups linked by roads, whatever their direction,
+// each region lists its city names in order and the regions are ordered by their first city
+func Regions(worldMap types.World) [][]string {
+	seen := make(map[*types.City]bool)
+	regions := make([][]string, 0)
+
+	for _, name := range worldMap.Names() {
+		city := worldMap.GetCity(name)
+		if seen[city] {
+			continue
+		}
+
+		region := make([]string, 0)
+		queue := []*types.City{city}
+		seen[city] = true
+
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			region = append(region, current.Name)
+
+			for _, neighbour := range neighbourhood(current) {
+				if !seen[neighbour] {
+					seen[neighbour] = true
+					queue = append(queue, neighbour)
+				}
+			}
+		}
+
+		sort.Strings(region)
+		regions = append(regions, region)
+	}
+
+	return regions
+}
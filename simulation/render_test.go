@@ -0,0 +1,97 @@
+package simulation
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGrid(t *testing.T) {
+	world, err := GenerateGrid(10, 0, types.FourWay, rand.New(rand.NewSource(1))) //nolint:gosec
+	require.NoError(t, err)
+	require.Len(t, world, 10)
+	// Without roads every city is still linked to an adjacent one, both ways
+	for _, name := range world.Names() {
+		city := world.GetCity(name)
+		require.NotEmpty(t, city.Neighbours, name)
+	}
+
+	require.Empty(t, CheckSymmetry(world, types.FourWay))
+
+	world, err = GenerateGrid(9, 1, types.FourWay, rand.New(rand.NewSource(1))) //nolint:gosec
+	require.NoError(t, err)
+	require.Len(t, Regions(world), 1)
+	require.Equal(t, world.GetCity("City2"), world.GetCity("City5").Neighbours[types.North])
+
+	_, err = GenerateGrid(1, 1, types.FourWay, rand.New(rand.NewSource(1))) //nolint:gosec
+	require.ErrorIs(t, err, ErrInvalidGrid)
+}
+
+func TestGenerateGridRoundTrip(t *testing.T) {
+	for _, directions := range types.DirectionSets {
+		t.Run(directions.Name, func(t *testing.T) {
+			world, err := GenerateGrid(30, 0.6, directions, rand.New(rand.NewSource(3))) //nolint:gosec
+			require.NoError(t, err)
+			require.Empty(t, CheckSymmetry(world, directions))
+
+			for _, name := range world.Names() {
+				for _, link := range world.GetCity(name).Links() {
+					require.True(t, directions.Contains(link.Direction), "%s uses %s", name, types.GetDirection(link.Direction))
+				}
+			}
+			// The printed map names its direction set and loads back whatever the default set
+			out := &bytes.Buffer{}
+			PrintWorld(out, world, directions)
+
+			_, fileName := createTempFile(t)
+			require.NoError(t, os.WriteFile(fileName, out.Bytes(), 0600))
+
+			loaded, err := LoadMap(fileName, types.FourWay)
+			require.NoError(t, err)
+			require.Equal(t, directions.Name, loaded.Directions.Name)
+
+			printed := &bytes.Buffer{}
+			PrintWorld(printed, loaded.World, loaded.Directions)
+			require.Equal(t, out.String(), printed.String())
+		})
+	}
+
+	_, err := GenerateGrid(9, 1, types.DirectionSet{Name: "12way"}, rand.New(rand.NewSource(1))) //nolint:gosec
+	require.ErrorIs(t, err, ErrInvalidGrid)
+}
+
+func TestRegions(t *testing.T) {
+	testWorld, _, err := createTestWorldWithNeighbours(4, [][]int{
+		{1},
+		{},
+		{3},
+		{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"testCity_0", "testCity_1"}, {"testCity_2", "testCity_3"}}, Regions(testWorld))
+
+	out := &bytes.Buffer{}
+	PrintDOT(out, testWorld)
+	require.Equal(t, "digraph world {\n  \"testCity_0\";\n"+
+		"  \"testCity_0\" -> \"testCity_1\" [label=\"north\", weight=1, length=1];\n  \"testCity_1\";\n  \"testCity_2\";\n"+
+		"  \"testCity_2\" -> \"testCity_3\" [label=\"north\", weight=1, length=1];\n  \"testCity_3\";\n}\n",
+		out.String())
+}
+
+func TestPrintDOTRoadsAndAttributes(t *testing.T) {
+	_, fileName := createTempFile(t)
+	require.NoError(t, os.WriteFile(fileName, []byte("Bonn north=Koln:3:2 @capital defense=2\nKoln south=Bonn\n"), 0600))
+
+	loaded, err := LoadMap(fileName, types.FourWay)
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	PrintDOT(out, loaded.World)
+	require.Equal(t, "digraph world {\n  \"Bonn\" [\"capital\"=\"true\", \"defense\"=\"2\"];\n"+
+		"  \"Bonn\" -> \"Koln\" [label=\"north\", weight=3, length=2];\n  \"Koln\";\n"+
+		"  \"Koln\" -> \"Bonn\" [label=\"south\", weight=1, length=1];\n}\n", out.String())
+}
@@ -0,0 +1,290 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// The report formats, the text format is the banner output of the run command
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the report formats
+var Formats = []string{FormatText, FormatJSON, FormatCSV, FormatMarkdown}
+
+// Report is the outcome of a run: its metadata, the destroyed cities, the cities
+// left with their roads and the aliens left with their locations
+type Report struct {
+	Metadata  ReportMetadata  `json:"metadata"`
+	Destroyed []DestroyedCity `json:"destroyed"`
+	Cities    []CityReport    `json:"cities"`
+	Aliens    []AlienReport   `json:"aliens"`
+}
+
+// ReportMetadata describes the run, the caller fills in what the simulation does not know
+type ReportMetadata struct {
+	Map           string `json:"map,omitempty"`
+	Seed          int64  `json:"seed"`
+	Aliens        int    `json:"aliens"`
+	Iterations    int    `json:"iterations"`
+	MaxIterations int    `json:"max_iterations"`
+	CitiesLeft    int    `json:"cities_left"`
+	AliensLeft    int    `json:"aliens_left"`
+	Stopped       bool   `json:"stopped"`
+}
+
+// DestroyedCity is an entry of the destroyed-city log
+type DestroyedCity struct {
+	Iteration int      `json:"iteration"`
+	City      string   `json:"city"`
+	Aliens    []string `json:"aliens"`
+}
+
+// CityReport is a city left standing along with its roads
+type CityReport struct {
+	Name  string       `json:"name"`
+	Roads []RoadReport `json:"roads"`
+}
+
+// RoadReport is a road leaving a city
+type RoadReport struct {
+	Direction string `json:"direction"`
+	To        string `json:"to"`
+	Weight    int    `json:"weight"`
+	Length    int    `json:"length"`
+	Blocked   bool   `json:"blocked,omitempty"`
+}
+
+// AlienReport is an alien left alive or trapped
+type AlienReport struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Faction  string `json:"faction,omitempty"`
+	Class    string `json:"class"`
+	Status   string `json:"status"`
+	Location string `json:"location"`
+}
+
+// Report builds the report of the simulation as it stands
+func (s *Simulation) Report() Report {
+	report := Report{
+		Metadata: ReportMetadata{
+			Aliens:        len(s.roster),
+			Iterations:    s.count,
+			MaxIterations: s.maxIterations,
+		},
+		Destroyed: make([]DestroyedCity, 0),
+		Cities:    make([]CityReport, 0),
+		Aliens:    make([]AlienReport, 0),
+	}
+
+	for _, event := range s.events {
+		if event.Kind == EventCityDestroyed {
+			report.Destroyed = append(report.Destroyed, DestroyedCity{
+				Iteration: event.Iteration,
+				City:      event.City,
+				Aliens:    append([]string{}, event.Aliens...),
+			})
+		}
+	}
+
+	for _, city := range s.Cities() {
+		cityReport := CityReport{Name: city.Name, Roads: make([]RoadReport, 0)}
+
+		for _, link := range city.Links() {
+			cityReport.Roads = append(cityReport.Roads, RoadReport{
+				Direction: types.GetDirection(link.Direction),
+				To:        link.To.Name,
+				Weight:    city.RoadWeight(link.Direction),
+				Length:    city.RoadLength(link.Direction),
+				Blocked:   link.Road.Blocked,
+			})
+		}
+
+		report.Cities = append(report.Cities, cityReport)
+	}
+
+	for _, alien := range s.Aliens() {
+		if alien.Status == types.Dead {
+			continue
+		}
+
+		report.Aliens = append(report.Aliens, AlienReport{
+			ID:       alien.ID,
+			Name:     alien.String(),
+			Faction:  alien.Faction,
+			Class:    alien.Class.String(),
+			Status:   alien.Status.String(),
+			Location: alien.Position(),
+		})
+	}
+
+	report.Metadata.CitiesLeft = len(report.Cities)
+	report.Metadata.AliensLeft = len(report.Aliens)
+
+	return report
+}
+
+// WriteReport writes the report in the json, csv or markdown format
+func WriteReport(w io.Writer, report Report, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report)
+	case FormatCSV:
+		return writeCSVReport(w, report)
+	case FormatMarkdown:
+		writeMarkdownReport(w, report)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// writeCSVReport writes one table per section, each with its header row, separated by an empty line
+func writeCSVReport(w io.Writer, report Report) error {
+	sections := make([][][]string, 0, 4)
+	sections = append(sections, append([][]string{{"key", "value"}}, metadataRows(report.Metadata)...))
+
+	destroyed := [][]string{{"iteration", "city", "aliens"}}
+	for _, entry := range report.Destroyed {
+		destroyed = append(destroyed, []string{strconv.Itoa(entry.Iteration), entry.City, strings.Join(entry.Aliens, " ")})
+	}
+
+	cities := [][]string{{"city", "direction", "to", "weight", "length", "blocked"}}
+	for _, city := range report.Cities {
+		if len(city.Roads) == 0 {
+			cities = append(cities, []string{city.Name, "", "", "", "", ""})
+		}
+
+		for _, road := range city.Roads {
+			cities = append(cities, []string{city.Name, road.Direction, road.To, strconv.Itoa(road.Weight),
+				strconv.Itoa(road.Length), strconv.FormatBool(road.Blocked)})
+		}
+	}
+
+	aliens := [][]string{{"id", "name", "faction", "class", "status", "location"}}
+	for _, alien := range report.Aliens {
+		aliens = append(aliens, []string{strconv.Itoa(alien.ID), alien.Name, alien.Faction, alien.Class, alien.Status, alien.Location})
+	}
+
+	sections = append(sections, destroyed, cities, aliens)
+	writer := csv.NewWriter(w)
+
+	for i, section := range sections {
+		if i > 0 {
+			writer.Flush()
+			fmt.Fprintln(w)
+		}
+
+		if err := writer.WriteAll(section); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMarkdownReport writes one heading and one table per section
+func writeMarkdownReport(w io.Writer, report Report) {
+	fmt.Fprintln(w, "# Invasion Report")
+	fmt.Fprintln(w)
+	writeMarkdownTable(w, []string{"Key", "Value"}, metadataRows(report.Metadata))
+
+	destroyed := make([][]string, 0, len(report.Destroyed))
+	for _, entry := range report.Destroyed {
+		destroyed = append(destroyed, []string{strconv.Itoa(entry.Iteration), entry.City, strings.Join(entry.Aliens, ", ")})
+	}
+
+	fmt.Fprintln(w, "## Destroyed Cities")
+	fmt.Fprintln(w)
+	writeMarkdownTable(w, []string{"Iteration", "City", "Aliens"}, destroyed)
+
+	cities := make([][]string, 0, len(report.Cities))
+	for _, city := range report.Cities {
+		// The roads are written as in the map files, with their weight and length
+		roads := make([]string, 0, len(city.Roads))
+		for _, road := range city.Roads {
+			entry := fmt.Sprintf("%s=%s:%d:%d", road.Direction, road.To, road.Weight, road.Length)
+			if road.Blocked {
+				entry += " (blocked)"
+			}
+
+			roads = append(roads, entry)
+		}
+
+		cities = append(cities, []string{city.Name, strings.Join(roads, ", ")})
+	}
+
+	fmt.Fprintln(w, "## Cities Left")
+	fmt.Fprintln(w)
+	writeMarkdownTable(w, []string{"City", "Roads"}, cities)
+
+	aliens := make([][]string, 0, len(report.Aliens))
+	for _, alien := range report.Aliens {
+		aliens = append(aliens, []string{strconv.Itoa(alien.ID), alien.Name, alien.Faction, alien.Class, alien.Status, alien.Location})
+	}
+
+	fmt.Fprintln(w, "## Aliens Left")
+	fmt.Fprintln(w)
+	writeMarkdownTable(w, []string{"Id", "Name", "Faction", "Class", "Status", "Location"}, aliens)
+}
+
+// writeMarkdownTable writes the rows as a markdown table, an empty table reads "None"
+func writeMarkdownTable(w io.Writer, header []string, rows [][]string) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "None")
+		fmt.Fprintln(w)
+
+		return
+	}
+
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "| %s |\n", strings.Join(separator, " | "))
+
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	fmt.Fprintln(w)
+}
+
+// metadataRows lists the metadata as key value pairs
+func metadataRows(metadata ReportMetadata) [][]string {
+	return [][]string{
+		{"map", metadata.Map},
+		{"seed", strconv.FormatInt(metadata.Seed, 10)},
+		{"aliens", strconv.Itoa(metadata.Aliens)},
+		{"iterations", strconv.Itoa(metadata.Iterations)},
+		{"max_iterations", strconv.Itoa(metadata.MaxIterations)},
+		{"cities_left", strconv.Itoa(metadata.CitiesLeft)},
+		{"aliens_left", strconv.Itoa(metadata.AliensLeft)},
+		{"stopped", strconv.FormatBool(metadata.Stopped)},
+	}
+}
@@ -0,0 +1,73 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+// createReportSimulation pins one alien of the red faction in testCity_2 and destroys testCity_0,
+// the road from testCity_1 has a weight of 3 and a length of 2
+func createReportSimulation(t *testing.T) *Simulation {
+	t.Helper()
+
+	world, cities, err := createTestWorldWithNeighbours(3, [][]int{{1}, {2}, {1}})
+	require.NoError(t, err)
+	cities[1].SetRoadWeight(types.North, 3)
+	cities[1].SetRoadLength(types.North, 2)
+
+	simulator, err := NewSimulation(world, 1, 10, WithOutput(ioutil.Discard),
+		WithPinnedAliens([]PlacementEntry{{City: "testCity_2", Faction: "red"}}))
+	require.NoError(t, err)
+	require.NoError(t, simulator.InitAliens(cities, 1))
+	require.NoError(t, simulator.DestroyCity("testCity_0"))
+
+	return simulator
+}
+
+func TestReport(t *testing.T) {
+	report := createReportSimulation(t).Report()
+
+	require.Equal(t, ReportMetadata{Aliens: 1, MaxIterations: 10, CitiesLeft: 2, AliensLeft: 1}, report.Metadata)
+	require.Equal(t, []DestroyedCity{{City: "testCity_0", Aliens: []string{}}}, report.Destroyed)
+	require.Equal(t, []CityReport{
+		{Name: "testCity_1", Roads: []RoadReport{{Direction: "north", To: "testCity_2", Weight: 3, Length: 2}}},
+		{Name: "testCity_2", Roads: []RoadReport{{Direction: "north", To: "testCity_1", Weight: 1, Length: 1}}},
+	}, report.Cities)
+	require.Len(t, report.Aliens, 1)
+	require.Equal(t, AlienReport{ID: 0, Name: report.Aliens[0].Name, Faction: "red", Class: "regular",
+		Status: "alive", Location: "testCity_2"}, report.Aliens[0])
+}
+
+func TestWriteReport(t *testing.T) {
+	report := createReportSimulation(t).Report()
+	report.Metadata.Seed = 7
+
+	out := &bytes.Buffer{}
+	require.NoError(t, WriteReport(out, report, FormatJSON))
+
+	decoded := Report{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Equal(t, report, decoded)
+	require.Contains(t, out.String(), `"weight": 3,`)
+	require.Contains(t, out.String(), `"length": 2`)
+
+	out.Reset()
+	require.NoError(t, WriteReport(out, report, FormatCSV))
+	require.Contains(t, out.String(), "key,value\n")
+	require.Contains(t, out.String(), "seed,7\n")
+	require.Contains(t, out.String(), "\n\niteration,city,aliens\n0,testCity_0,\n")
+	require.Contains(t, out.String(), "\n\ncity,direction,to,weight,length,blocked\ntestCity_1,north,testCity_2,3,2,false\n")
+	require.Contains(t, out.String(), "testCity_2,north,testCity_1,1,1,false\n")
+
+	out.Reset()
+	require.NoError(t, WriteReport(out, report, FormatMarkdown))
+	require.Contains(t, out.String(), "## Cities Left\n\n| City | Roads |\n| --- | --- |\n| testCity_1 | north=testCity_2:3:2 |\n")
+	require.Contains(t, out.String(), "| red | regular | alive | testCity_2 |")
+
+	require.ErrorIs(t, WriteReport(out, report, "xml"), ErrUnknownFormat)
+}
@@ -0,0 +1,139 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidRoadDamage = errors.New("invalid road damage model")
+
+// RoadDamage holds the probabilities that an alien destroys or blocks the road
+// it takes, both ways between the two cities.
+type RoadDamage struct {
+	Destroy float64
+	Block   float64
+}
+
+// Validate checks that the probabilities are valid and add up to at most 1
+func (m RoadDamage) Validate() error {
+	if m.Destroy < 0 || m.Block < 0 || m.Destroy+m.Block > 1 {
+		return ErrInvalidRoadDamage
+	}
+
+	return nil
+}
+
+// damageRoad gives the alien taking the road a chance to destroy or block it
+func (s *Simulation) damageRoad(alien *types.Alien, from *types.City, direction types.Direction) {
+	if s.roadDamage.Destroy == 0 && s.roadDamage.Block == 0 {
+		return
+	}
+
+	to := from.Neighbours[direction]
+
+	switch draw := s.rng.Float64(); {
+	case draw < s.roadDamage.Destroy:
+		s.destroyRoad(from, to, []*types.Alien{alien})
+	case draw < s.roadDamage.Destroy+s.roadDamage.Block:
+		s.blockRoad(from, to, true)
+	}
+}
+
+// DestroyRoad destroys the road leaving the city in the given direction, along with the roads leading back
+func (s *Simulation) DestroyRoad(city string, direction types.Direction) error {
+	from, to, err := s.getRoad(city, direction)
+	if err != nil {
+		return err
+	}
+
+	s.destroyRoad(from, to, nil)
+
+	return nil
+}
+
+// BlockRoad blocks or reopens the road leaving the city in the given direction, along with the roads leading back
+func (s *Simulation) BlockRoad(city string, direction types.Direction, blocked bool) error {
+	from, to, err := s.getRoad(city, direction)
+	if err != nil {
+		return err
+	}
+
+	s.blockRoad(from, to, blocked)
+
+	return nil
+}
+
+// AddRoad builds a road leaving the from city in the given direction, the road back has to be added on its own
+func (s *Simulation) AddRoad(from string, direction types.Direction, to string) error {
+	if !s.directions.Contains(direction) {
+		return fmt.Errorf("%w: %s is not part of the %s directions", types.ErrInvalidDirection,
+			types.GetDirection(direction), s.directions.Name)
+	}
+
+	for _, name := range []string{from, to} {
+		if err := s.checkStanding(name); err != nil {
+			return err
+		}
+	}
+
+	s.worldMap.AddRoad(from, direction, to) //nolint:errcheck // both cities stand
+
+	s.emit(Event{Kind: EventRoadAdded, From: s.worldMap.GetCity(from).Name, To: s.worldMap.GetCity(to).Name})
+
+	return nil
+}
+
+// getRoad returns the cities linked by the road leaving the city in the given direction
+func (s *Simulation) getRoad(city string, direction types.Direction) (*types.City, *types.City, error) {
+	from := s.worldMap.GetCity(city)
+	if from == nil {
+		return nil, nil, fmt.Errorf("%w: %s", types.ErrCityNotFound, city)
+	}
+
+	to := from.Neighbours[direction]
+	if to == nil {
+		return nil, nil, fmt.Errorf("%w: %s %s", types.ErrRoadNotFound, city, types.GetDirection(direction))
+	}
+
+	return from, to, nil
+}
+
+// linkingRoads returns the roads linking the two cities, in both directions
+func linkingRoads(from, to *types.City) []types.RoadRef {
+	roads := make([]types.RoadRef, 0, 2)
+
+	for _, pair := range [][2]*types.City{{from, to}, {to, from}} {
+		for _, link := range pair[0].Links() {
+			if link.To == pair[1] {
+				roads = append(roads, types.RoadRef{From: pair[0], Direction: link.Direction})
+			}
+		}
+	}
+
+	return roads
+}
+
+// destroyRoad removes the roads linking the two cities, the aliens are credited with the destruction
+func (s *Simulation) destroyRoad(from, to *types.City, aliens []*types.Alien) {
+	for _, road := range linkingRoads(from, to) {
+		s.worldMap.RemoveRoad(road.From.Name, road.Direction) //nolint:errcheck // the road exists
+	}
+
+	s.emit(Event{Kind: EventRoadDestroyed, From: from.Name, To: to.Name, Aliens: alienNames(aliens)})
+}
+
+// blockRoad blocks or reopens the roads linking the two cities
+func (s *Simulation) blockRoad(from, to *types.City, blocked bool) {
+	for _, road := range linkingRoads(from, to) {
+		road.From.SetRoadBlocked(road.Direction, blocked)
+	}
+
+	kind := EventRoadReopened
+	if blocked {
+		kind = EventRoadBlocked
+	}
+
+	s.emit(Event{Kind: kind, From: from.Name, To: to.Name})
+}
@@ -0,0 +1,136 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldRoads(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(3, [][]int{
+		{1, 2},
+		{0},
+		{0},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []types.RoadRef{{From: cities[1], Direction: types.North}, {From: cities[2], Direction: types.North}},
+		cities[0].InboundRoads())
+
+	neighbour, err := testWorld.RemoveRoad("testCity_0", types.North)
+	require.NoError(t, err)
+	require.Equal(t, cities[1], neighbour)
+	require.Len(t, cities[0].InboundRoads(), 2)
+	require.Empty(t, cities[1].InboundRoads())
+
+	_, err = testWorld.RemoveRoad("testCity_0", types.North)
+	require.ErrorIs(t, err, types.ErrRoadNotFound)
+	require.ErrorIs(t, testWorld.AddRoad("testCity_0", types.East, "nowhere"), types.ErrCityNotFound)
+
+	require.NoError(t, testWorld.AddRoad("testCity_1", types.East, "testCity_2"))
+	require.Equal(t, []types.RoadRef{{From: cities[0], Direction: types.South}, {From: cities[1], Direction: types.East}},
+		cities[2].InboundRoads())
+	// Replacing a road updates the index of the former neighbour
+	require.NoError(t, testWorld.AddRoad("testCity_1", types.East, "testCity_0"))
+	require.Equal(t, []types.RoadRef{{From: cities[0], Direction: types.South}}, cities[2].InboundRoads())
+	require.Len(t, cities[0].InboundRoads(), 3)
+
+	require.NoError(t, testWorld.RemoveCityRoads("testCity_0"))
+
+	for _, city := range cities {
+		require.Empty(t, city.InboundRoads())
+	}
+
+	require.Empty(t, cities[0].Neighbours)
+	require.Empty(t, cities[1].Neighbours)
+}
+
+func TestRoadEvents(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+
+	require.NoError(t, simulation.BlockRoad("testCity_0", types.North, true))
+	require.True(t, cities[0].RoadBlocked(types.North))
+	require.True(t, cities[1].RoadBlocked(types.North))
+
+	_, err = cities[0].PickRandomDirection(simulation.rng)
+	require.ErrorIs(t, err, types.ErrNoNeighbours)
+
+	require.NoError(t, simulation.BlockRoad("testCity_1", types.North, false))
+	require.False(t, cities[0].RoadBlocked(types.North))
+
+	require.NoError(t, simulation.DestroyRoad("testCity_0", types.North))
+	require.Empty(t, cities[0].Neighbours)
+	require.Empty(t, cities[1].Neighbours)
+	require.ErrorIs(t, simulation.DestroyRoad("testCity_0", types.North), types.ErrRoadNotFound)
+
+	require.NoError(t, simulation.AddRoad("testCity_0", types.East, "testCity_1"))
+	require.Equal(t, cities[1], cities[0].Neighbours[types.East])
+
+	kinds := make([]EventKind, 0)
+	for _, event := range simulation.Events() {
+		kinds = append(kinds, event.Kind)
+	}
+
+	require.Equal(t, []EventKind{EventRoadBlocked, EventRoadReopened, EventRoadDestroyed, EventRoadAdded}, kinds)
+}
+
+func TestAddRoadDirections(t *testing.T) {
+	testWorld, cities := createTestWorld(2)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+	require.ErrorIs(t, simulation.AddRoad("testCity_0", types.NorthEast, "testCity_1"), types.ErrInvalidDirection)
+	require.Empty(t, cities[0].Neighbours)
+	require.Empty(t, simulation.Events())
+
+	simulation, err = NewSimulation(testWorld, 1, 10, WithDirections(types.EightWay), WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+	require.NoError(t, simulation.AddRoad("testCity_0", types.NorthEast, "testCity_1"))
+	require.Equal(t, cities[1], cities[0].Neighbours[types.NorthEast])
+}
+
+func TestRoadDamage(t *testing.T) {
+	testCases := []struct {
+		name      string
+		model     RoadDamage
+		destroyed bool
+		blocked   bool
+	}{
+		{name: "no damage"},
+		{name: "destroy", model: RoadDamage{Destroy: 1}, destroyed: true},
+		{name: "block", model: RoadDamage{Block: 1}, blocked: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+				{1},
+				{0},
+			})
+			require.NoError(t, err)
+
+			simulation, err := NewSimulation(testWorld, 1, 10, WithRoadDamage(tc.model), WithOutput(ioutil.Discard))
+			require.NoError(t, err)
+
+			alien := types.NewAlien(0, "", cities[0])
+			simulation.addAlien(alien)
+			simulation.moveAliens()
+
+			// The alien reaches the other end of the road either way
+			require.Equal(t, cities[1], alien.City)
+			require.Equal(t, tc.destroyed, cities[1].Neighbours[types.North] == nil)
+			require.Equal(t, tc.blocked, cities[1].RoadBlocked(types.North))
+		})
+	}
+
+	_, err := NewSimulation(types.NewWorldMap(), 1, 1, WithRoadDamage(RoadDamage{Destroy: 0.6, Block: 0.6}))
+	require.Error(t, err)
+}
@@ -0,0 +1,431 @@
+package simulation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+const (
+	scheduleWhen = "when"
+	// randomTarget picks a random road instead of a named one, e.g. "block random road"
+	randomTarget = "random"
+	// neighboursTarget designates the neighbours of the city triggering a rule
+	neighboursTarget = "neighbours"
+)
+
+// ScenarioRule applies a scripted action at the start of an iteration, either once
+// at a given iteration, every given number of iterations or after a city was destroyed.
+type ScenarioRule struct {
+	At    int
+	Every int
+	// When is the city whose destruction triggers the rule
+	When string
+	// Text is the source of the rule, used in the reports
+	Text   string
+	action scenarioAction
+}
+
+// scenarioAction is the action of a scenario rule, fallen is the city triggering a when rule
+type scenarioAction interface {
+	apply(s *Simulation, fallen *fallenCity) error
+	cities() []string
+}
+
+type destroyRoadAction struct {
+	city      string
+	direction types.Direction
+	random    bool
+}
+
+type blockRoadAction struct {
+	city      string
+	direction types.Direction
+	random    bool
+	blocked   bool
+}
+
+type addRoadAction struct {
+	from      string
+	direction types.Direction
+	to        string
+}
+
+type destroyCityAction struct {
+	city string
+}
+
+type spawnAction struct {
+	count   int
+	city    string
+	faction string
+}
+
+type quarantineAction struct {
+	city       string
+	neighbours bool
+}
+
+// due checks whether the rule applies at the given iteration, a when rule
+// applies when its city is among the fallen ones and returns it
+func (r ScenarioRule) due(iteration int, fallen []fallenCity) (*fallenCity, bool) {
+	if r.When != "" {
+		for i := range fallen {
+			if types.NormalizeName(fallen[i].city.Name) == r.When {
+				return &fallen[i], true
+			}
+		}
+
+		return nil, false
+	}
+
+	if r.Every > 0 {
+		return nil, iteration > 0 && iteration%r.Every == 0
+	}
+
+	return nil, iteration == r.At
+}
+
+// Faction returns the faction of the aliens spawned by the rule, if any
+func (r ScenarioRule) Faction() string {
+	if spawn, ok := r.action.(spawnAction); ok {
+		return spawn.faction
+	}
+
+	return ""
+}
+
+// LoadScenario reads one scenario rule per line, blank lines and '#' comments are ignored
+func LoadScenario(filePath string) ([]ScenarioRule, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	defer file.Close()
+
+	rules := make([]ScenarioRule, 0)
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+
+		rule, err := ParseScenarioRule(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filePath, lineNumber, err)
+		}
+
+		if rule.action != nil {
+			rules = append(rules, rule)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// ParseScenarioRule parses a "<trigger>: <action>" rule, the trigger is either
+// "at <n>", "every <n>" or "when city <name> destroyed". A blank line gives an empty rule.
+func ParseScenarioRule(line string) (ScenarioRule, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return ScenarioRule{}, fmt.Errorf("%w: %q", err, line)
+	}
+
+	if len(tokens) == 0 {
+		return ScenarioRule{}, nil
+	}
+
+	rule := ScenarioRule{Text: strings.TrimSpace(strings.SplitN(line, commentPrefix, 2)[0])}
+
+	var rest []token
+
+	if !tokens[0].hasValue && tokens[0].key == scheduleWhen {
+		rest, err = parseWhen(tokens, &rule.When)
+	} else {
+		rest, err = parseSchedule(tokens, &rule.At, &rule.Every)
+	}
+
+	if err != nil {
+		return ScenarioRule{}, fmt.Errorf("%w: %q", ErrInvalidScenario, line)
+	}
+
+	if rule.action, err = parseAction(rest, rule.When != ""); err != nil {
+		return ScenarioRule{}, fmt.Errorf("%w: %q", ErrInvalidScenario, line)
+	}
+
+	return rule, nil
+}
+
+// parseWhen parses the leading "when city <name> destroyed:" tokens and returns the tokens left
+func parseWhen(tokens []token, city *string) ([]token, error) {
+	if len(tokens) < 4 || tokens[1].hasValue || tokens[1].key != "city" || tokens[2].hasValue ||
+		tokens[3].hasValue || tokens[3].key != "destroyed:" {
+		return nil, ErrInvalidScenario
+	}
+
+	*city = types.NormalizeName(tokens[2].key)
+
+	return tokens[4:], nil
+}
+
+// parseAction parses the action of a rule, the neighbours of the city are only known to when rules
+func parseAction(tokens []token, when bool) (scenarioAction, error) {
+	args := make([]string, 0, len(tokens))
+
+	for _, token := range tokens {
+		if token.hasValue && token.key != "faction" {
+			return nil, ErrInvalidScenario
+		}
+
+		args = append(args, token.key)
+	}
+
+	switch {
+	case len(args) == 0:
+		return nil, ErrInvalidScenario
+	case args[0] == "spawn":
+		count, city, faction, err := parseSpawn(tokens[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		return spawnAction{count: count, city: city, faction: faction}, nil
+	case args[0] == "quarantine" && len(args) == 2:
+		if args[1] == neighboursTarget && !tokens[1].quoted {
+			if !when {
+				return nil, ErrInvalidScenario
+			}
+
+			return quarantineAction{neighbours: true}, nil
+		}
+
+		return quarantineAction{city: args[1]}, nil
+	case args[0] == "destroy" && len(args) == 3 && args[1] == "city":
+		return destroyCityAction{city: args[2]}, nil
+	case args[0] == "add" && len(args) == 5 && args[1] == "road":
+		direction, err := types.ParseDirection(args[3])
+		if err != nil {
+			return nil, err
+		}
+
+		return addRoadAction{from: args[2], direction: direction, to: args[4]}, nil
+	case (args[0] == "destroy" || args[0] == "block") && len(args) == 3 && args[1] == randomTarget && args[2] == "road":
+		if args[0] == "destroy" {
+			return destroyRoadAction{random: true}, nil
+		}
+
+		return blockRoadAction{random: true, blocked: true}, nil
+	case (args[0] == "destroy" || args[0] == "block" || args[0] == "reopen") && len(args) == 4 && args[1] == "road":
+		direction, err := types.ParseDirection(args[3])
+		if err != nil {
+			return nil, err
+		}
+
+		if args[0] == "destroy" {
+			return destroyRoadAction{city: args[2], direction: direction}, nil
+		}
+
+		return blockRoadAction{city: args[2], direction: direction, blocked: args[0] == "block"}, nil
+	}
+
+	return nil, ErrInvalidScenario
+}
+
+func (a destroyRoadAction) apply(s *Simulation, _ *fallenCity) error {
+	if !a.random {
+		return s.DestroyRoad(a.city, a.direction)
+	}
+
+	road, ok := s.randomRoad(false)
+	if !ok {
+		return types.ErrRoadNotFound
+	}
+
+	return s.DestroyRoad(road.From.Name, road.Direction)
+}
+
+func (a destroyRoadAction) cities() []string {
+	if a.random {
+		return nil
+	}
+
+	return []string{a.city}
+}
+
+func (a blockRoadAction) apply(s *Simulation, _ *fallenCity) error {
+	if !a.random {
+		return s.BlockRoad(a.city, a.direction, a.blocked)
+	}
+
+	road, ok := s.randomRoad(true)
+	if !ok {
+		return types.ErrRoadNotFound
+	}
+
+	return s.BlockRoad(road.From.Name, road.Direction, true)
+}
+
+func (a blockRoadAction) cities() []string {
+	if a.random {
+		return nil
+	}
+
+	return []string{a.city}
+}
+
+func (a addRoadAction) apply(s *Simulation, _ *fallenCity) error {
+	return s.AddRoad(a.from, a.direction, a.to)
+}
+
+func (a addRoadAction) cities() []string {
+	return []string{a.from, a.to}
+}
+
+func (a destroyCityAction) apply(s *Simulation, _ *fallenCity) error {
+	return s.DestroyCity(a.city)
+}
+
+func (a destroyCityAction) cities() []string {
+	return []string{a.city}
+}
+
+func (a spawnAction) apply(s *Simulation, _ *fallenCity) error {
+	s.spawn(a.count, a.city, a.faction)
+
+	return nil
+}
+
+func (a spawnAction) cities() []string {
+	if a.city == "" {
+		return nil
+	}
+
+	return []string{a.city}
+}
+
+func (a quarantineAction) apply(s *Simulation, fallen *fallenCity) error {
+	if !a.neighbours {
+		return s.QuarantineCity(a.city)
+	}
+
+	for _, city := range fallen.neighbours {
+		if s.standing(city) && !s.quarantined[city] {
+			s.QuarantineCity(city.Name) //nolint:errcheck // the city stands
+		}
+	}
+
+	return nil
+}
+
+func (a quarantineAction) cities() []string {
+	if a.neighbours {
+		return nil
+	}
+
+	return []string{a.city}
+}
+
+// DestroyCity destroys the standing city along with the aliens inside
+func (s *Simulation) DestroyCity(name string) error {
+	if err := s.checkStanding(name); err != nil {
+		return err
+	}
+
+	city := s.worldMap.GetCity(name)
+	s.killAliens(sortedAliens(city.OccupiedAliens))
+	s.distroyCity(city)
+
+	return nil
+}
+
+// randomRoad picks a road leaving a standing city, only among the open roads when open is set
+func (s *Simulation) randomRoad(open bool) (types.RoadRef, bool) {
+	roads := make([]types.RoadRef, 0)
+
+	for _, name := range s.worldMap.Names() {
+		city := s.worldMap.GetCity(name)
+		if !s.standing(city) {
+			continue
+		}
+
+		for _, link := range city.Links() {
+			if !(open && link.Road.Blocked) {
+				roads = append(roads, types.RoadRef{From: city, Direction: link.Direction})
+			}
+		}
+	}
+
+	if len(roads) == 0 {
+		return types.RoadRef{}, false
+	}
+
+	return roads[s.rng.Intn(len(roads))], true
+}
+
+// checkScenarioCities checks that the cities named by the scenario exist
+func (s *Simulation) checkScenarioCities() error {
+	for _, rule := range s.scenario {
+		names := rule.action.cities()
+		if rule.When != "" {
+			names = append(names, rule.When)
+		}
+
+		for _, name := range names {
+			if s.worldMap.GetCity(name) == nil {
+				return fmt.Errorf("%w: %s", ErrUnknownCity, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// scenarioPending checks whether a scheduled scenario rule can still land aliens
+func (s *Simulation) scenarioPending() bool {
+	for _, rule := range s.scenario {
+		if _, ok := rule.action.(spawnAction); ok && rule.When == "" && (rule.Every > 0 || rule.At >= s.count) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyScenario applies the timed rules due at the current iteration in order, then the
+// when rules triggered by the cities destroyed since the previous call, including the ones
+// destroyed by the timed rules, whatever the order of the rules. The cities destroyed by
+// the when rules trigger the next call. The actions which cannot be applied anymore are
+// reported and skipped.
+func (s *Simulation) applyScenario() {
+	for _, rule := range s.scenario {
+		if _, ok := rule.due(s.count, nil); ok && rule.When == "" {
+			s.applyRule(rule, nil)
+		}
+	}
+
+	fallen := s.scenarioFallen
+	s.scenarioFallen = nil
+
+	for _, rule := range s.scenario {
+		if city, ok := rule.due(s.count, fallen); ok && rule.When != "" {
+			s.applyRule(rule, city)
+		}
+	}
+}
+
+// applyRule applies the action of the rule, a failure is reported as a skipped rule
+func (s *Simulation) applyRule(rule ScenarioRule, fallen *fallenCity) {
+	if err := rule.action.apply(s, fallen); err != nil {
+		s.emit(Event{Kind: EventScenarioSkipped, Detail: fmt.Sprintf("%q skipped, %s", rule.Text, err)})
+	}
+}
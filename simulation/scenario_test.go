@@ -0,0 +1,220 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScenarioRule(t *testing.T) {
+	testCases := []struct {
+		name     string
+		line     string
+		expected ScenarioRule
+		err      error
+	}{
+		{name: "blank", line: "  # nothing", expected: ScenarioRule{}},
+		{
+			name: "destroy road",
+			line: "at 100: destroy road Berlin north",
+			expected: ScenarioRule{At: 100, Text: "at 100: destroy road Berlin north",
+				action: destroyRoadAction{city: "Berlin", direction: types.North}},
+		},
+		{
+			name: "spawn",
+			line: `at 200: spawn 3 at "New York" faction=red # reinforcements`,
+			expected: ScenarioRule{At: 200, Text: `at 200: spawn 3 at "New York" faction=red`,
+				action: spawnAction{count: 3, city: "New York", faction: "red"}},
+		},
+		{
+			name: "block random road",
+			line: "every 50: block random road",
+			expected: ScenarioRule{Every: 50, Text: "every 50: block random road",
+				action: blockRoadAction{random: true, blocked: true}},
+		},
+		{
+			name: "reopen road",
+			line: "at 3: reopen road Bonn south",
+			expected: ScenarioRule{At: 3, Text: "at 3: reopen road Bonn south",
+				action: blockRoadAction{city: "Bonn", direction: types.South}},
+		},
+		{
+			name: "add road",
+			line: "at 3: add road Bonn east Koln",
+			expected: ScenarioRule{At: 3, Text: "at 3: add road Bonn east Koln",
+				action: addRoadAction{from: "Bonn", direction: types.East, to: "Koln"}},
+		},
+		{
+			name: "quarantine neighbours",
+			line: "when city Bonn destroyed: quarantine neighbours",
+			expected: ScenarioRule{When: "Bonn", Text: "when city Bonn destroyed: quarantine neighbours",
+				action: quarantineAction{neighbours: true}},
+		},
+		{name: "neighbours without trigger", line: "at 3: quarantine neighbours", err: ErrInvalidScenario},
+		{name: "reopen random road", line: "at 3: reopen random road", err: ErrInvalidScenario},
+		{name: "unknown direction", line: "at 3: destroy road Bonn up-ish", err: ErrInvalidScenario},
+		{name: "unknown action", line: "at 3: nuke Bonn", err: ErrInvalidScenario},
+		{name: "invalid trigger", line: "when Bonn falls: destroy city Koln", err: ErrInvalidScenario},
+		{name: "missing action", line: "at 3:", err: ErrInvalidScenario},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseScenarioRule(tc.line)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rule)
+		})
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte("# what if\n\nat 1: destroy city Bonn\nwhen city Bonn destroyed: quarantine neighbours\n"), 0600)
+	require.NoError(t, err)
+
+	rules, err := LoadScenario(fileName)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, "Bonn", rules[1].When)
+
+	err = os.WriteFile(fileName, []byte("at 1: destroy city Bonn\nat 2: explode\n"), 0600)
+	require.NoError(t, err)
+
+	_, err = LoadScenario(fileName)
+	require.ErrorIs(t, err, ErrInvalidScenario)
+	require.Contains(t, err.Error(), ":2:")
+}
+
+func TestApplyScenario(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(4, [][]int{
+		{1},
+		{0, 2},
+		{1, 3},
+		{2},
+	})
+	require.NoError(t, err)
+
+	rules := make([]ScenarioRule, 0)
+
+	for _, line := range []string{
+		"at 1: destroy city testCity_0",
+		"when city testCity_0 destroyed: quarantine neighbours",
+		"at 2: destroy road testCity_3 north",
+		"at 2: destroy road testCity_3 north",
+		"every 2: spawn 1 at testCity_2",
+	} {
+		rule, err := ParseScenarioRule(line)
+		require.NoError(t, err)
+
+		rules = append(rules, rule)
+	}
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard), WithScenario(rules))
+	require.NoError(t, err)
+
+	simulation.count = 1
+	simulation.applyScenario()
+	// The destroyed city triggers the quarantine of its neighbour
+	require.Nil(t, testWorld.GetCity("testCity_0"))
+	require.True(t, cities[2].RoadBlocked(types.North))
+	require.Equal(t, []string{"testCity_1"}, simulation.HumanResult().Quarantined)
+
+	simulation.applyPolicies()
+	simulation.count = 2
+	simulation.applyScenario()
+	// The road is gone, destroying it again is skipped and reported
+	require.Nil(t, cities[3].Neighbours[types.North])
+	require.Len(t, cities[2].OccupiedAliens, 1)
+
+	events := simulation.Events()
+	skipped := 0
+
+	for _, event := range events {
+		if event.Kind == EventScenarioSkipped {
+			skipped++
+		}
+	}
+
+	require.Equal(t, 1, skipped)
+	require.True(t, simulation.scenarioPending())
+}
+
+func TestApplyScenarioWhenRuleBeforeItsTrigger(t *testing.T) {
+	testWorld, _, err := createTestWorldWithNeighbours(3, [][]int{
+		{1},
+		{0, 2},
+		{1},
+	})
+	require.NoError(t, err)
+
+	rules := make([]ScenarioRule, 0)
+
+	for _, line := range []string{
+		"when city testCity_0 destroyed: quarantine neighbours",
+		"when city testCity_2 destroyed: destroy city testCity_1",
+		"at 1: destroy city testCity_0",
+		"at 1: destroy city testCity_2",
+	} {
+		rule, err := ParseScenarioRule(line)
+		require.NoError(t, err)
+
+		rules = append(rules, rule)
+	}
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard), WithScenario(rules))
+	require.NoError(t, err)
+
+	simulation.count = 1
+	simulation.applyScenario()
+	// The when rules listed first are triggered by the cities destroyed by the later timed rules
+	require.Equal(t, []string{"testCity_1"}, simulation.HumanResult().Quarantined)
+	require.Nil(t, testWorld.GetCity("testCity_1"))
+	// The policies forgetting the fallen cities do not affect the scenario
+	simulation.applyPolicies()
+	simulation.count = 2
+	simulation.applyScenario()
+	require.Len(t, simulation.HumanResult().Quarantined, 1)
+}
+
+func TestApplyScenarioWhenRuleNormalizesTheCity(t *testing.T) {
+	testWorld := types.NewWorldMap()
+	for _, name := range []string{"Zürich", "Bern"} {
+		require.NoError(t, testWorld.AddCity(&types.City{Name: name, Neighbours: make(map[types.Direction]*types.City)}))
+	}
+
+	rules := make([]ScenarioRule, 0)
+
+	for _, line := range []string{"when city Zu\u0308rich destroyed: destroy city Bern", "at 0: destroy city Zürich"} {
+		rule, err := ParseScenarioRule(line)
+		require.NoError(t, err)
+
+		rules = append(rules, rule)
+	}
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard), WithScenario(rules))
+	require.NoError(t, err)
+
+	simulation.applyScenario()
+	require.Nil(t, testWorld.GetCity("Zürich"))
+	require.Nil(t, testWorld.GetCity("Bern"))
+}
+
+func TestScenarioUnknownCity(t *testing.T) {
+	testWorld, cities := createTestWorld(2)
+
+	rule, err := ParseScenarioRule("when city Atlantis destroyed: quarantine neighbours")
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard), WithScenario([]ScenarioRule{rule}))
+	require.NoError(t, err)
+	require.ErrorIs(t, simulation.InitAliens(cities, 1), ErrUnknownCity)
+}
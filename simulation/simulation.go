@@ -1,11 +1,14 @@
 package simulation
 
 import (
-	"crypto/rand"
 	"errors"
 	"fmt"
-	"math/big"
+	"io"
+	"math/rand"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/munna0908/alien-invasion/types"
 )
@@ -13,32 +16,305 @@ import (
 var (
 	ErrInvalidCityCount   = errors.New("cities count too low")
 	ErrInvalidAliensCount = errors.New("invalid aliens count")
+	ErrInvalidCapacity    = errors.New("invalid city capacity")
+	ErrStopped            = errors.New("invasion stopped")
 )
 
+// DefaultCityCapacity is the maximum number of aliens allocated to a city
+const DefaultCityCapacity = 2
+
 // Simulation simulates the alien invasion on the given cities
 type Simulation struct {
 	count         int
 	maxIterations int
 	worldMap      types.World
 	aliens        types.Aliens
+	roster        []*types.Alien
+	capacity      int
+	placement     PlacementStrategy
+	names         []string
+	factions      []string
+	pinned        []PlacementEntry
+	fightRule     FightRule
+	destroyedBy   map[string]int
+	battle        BattleModel
+	defense       DefenseModel
+	encounter     RoadEncounter
+	simultaneous  bool
+	roadDamage    RoadDamage
+	recovery      int
+	ruins         []*ruin
+	spawns        []SpawnRule
+	// bases are the configured motherships, resolved into motherships by NewSimulation
+	bases          []Mothership
+	mothershipRule MothershipRule
+	motherships    []*mothership
+	energy         EnergyModel
+	classes        ClassMix
+	policies       []Policy
+	fallen         []fallenCity
+	scenarioFallen []fallenCity
+	scenario       []ScenarioRule
+	quarantined    map[*types.City]bool
+	population     int
+	humans         HumanResult
+	events         []Event
+	out            io.Writer
+	rng            *rand.Rand
+	// generated are the names of the aliens beyond the configured ones, skipping the configured names
+	generated    []string
+	skippedNames int
+	configured   map[string]bool
+	// directions is the direction set of the map, the roads added during the run have to follow it
+	directions types.DirectionSet
+}
+
+// Option configures the optional settings of a Simulation
+type Option func(*Simulation)
+
+// WithCapacity sets the maximum number of aliens allocated to a city
+func WithCapacity(capacity int) Option {
+	return func(s *Simulation) {
+		s.capacity = capacity
+	}
+}
+
+// WithPlacement sets the strategy used to allocate the aliens
+func WithPlacement(strategy PlacementStrategy) Option {
+	return func(s *Simulation) {
+		s.placement = strategy
+	}
+}
+
+// WithAlienNames sets the names assigned to the aliens in order of their Id,
+// the aliens beyond the given names get a generated one.
+func WithAlienNames(names []string) Option {
+	return func(s *Simulation) {
+		s.names = names
+		s.configured = make(map[string]bool, len(names))
+
+		for _, name := range names {
+			s.configured[name] = true
+		}
+	}
+}
+
+// WithFactions sets the faction of the aliens in order of their Id
+func WithFactions(factions []string) Option {
+	return func(s *Simulation) {
+		s.factions = factions
+	}
+}
+
+// WithPinnedAliens places the first aliens in the given cities, the remaining
+// aliens are allocated by the placement strategy.
+func WithPinnedAliens(entries []PlacementEntry) Option {
+	return func(s *Simulation) {
+		s.pinned = entries
+	}
+}
+
+// WithFightRule sets the rule deciding whether co-located aliens fight
+func WithFightRule(rule FightRule) Option {
+	return func(s *Simulation) {
+		s.fightRule = rule
+	}
+}
+
+// WithBattleModel sets the probabilities of the battle outcomes
+func WithBattleModel(model BattleModel) Option {
+	return func(s *Simulation) {
+		s.battle = model
+	}
+}
+
+// WithDefenseModel sets the per defense level probabilities of the city defenses
+func WithDefenseModel(model DefenseModel) Option {
+	return func(s *Simulation) {
+		s.defense = model
+	}
+}
+
+// WithRoadFights makes the aliens meeting head-on on a multi-tick road fight there
+func WithRoadFights(enabled bool) Option {
+	return func(s *Simulation) {
+		if enabled {
+			s.encounter.Outcome = EncounterFight
+		}
+	}
+}
+
+// WithRoadEncounter sets the rule settling the aliens crossing each other on a road
+func WithRoadEncounter(rule RoadEncounter) Option {
+	return func(s *Simulation) {
+		s.encounter = rule
+	}
+}
+
+// WithRoadDamage sets the chances of an alien destroying or blocking the road it takes
+func WithRoadDamage(model RoadDamage) Option {
+	return func(s *Simulation) {
+		s.roadDamage = model
+	}
+}
+
+// WithRecovery rebuilds the destroyed cities after the given number of
+// iterations, zero keeps the cities destroyed for good.
+func WithRecovery(iterations int) Option {
+	return func(s *Simulation) {
+		s.recovery = iterations
+	}
+}
+
+// WithSpawns sets the schedule of the reinforcements landing during the invasion
+func WithSpawns(rules []SpawnRule) Option {
+	return func(s *Simulation) {
+		s.spawns = rules
+	}
+}
+
+// WithMotherships sets the alien bases along with the rule of their launches and destruction
+func WithMotherships(motherships []Mothership, rule MothershipRule) Option {
+	return func(s *Simulation) {
+		s.bases = motherships
+		s.mothershipRule = rule
+	}
+}
+
+// WithEnergyModel sets the energy model of the aliens
+func WithEnergyModel(model EnergyModel) Option {
+	return func(s *Simulation) {
+		s.energy = model
+	}
+}
+
+// WithClassMix sets the relative weights of the alien classes
+func WithClassMix(mix ClassMix) Option {
+	return func(s *Simulation) {
+		s.classes = mix
+	}
+}
+
+// WithPolicies sets the human policies, applied in order at every iteration
+func WithPolicies(policies []Policy) Option {
+	return func(s *Simulation) {
+		s.policies = policies
+	}
+}
+
+// WithScenario sets the scripted rules applied at the start of every iteration
+func WithScenario(rules []ScenarioRule) Option {
+	return func(s *Simulation) {
+		s.scenario = rules
+	}
+}
+
+// WithSimultaneousMoves makes every alien choose its move before any of them moves
+func WithSimultaneousMoves(enabled bool) Option {
+	return func(s *Simulation) {
+		s.simultaneous = enabled
+	}
+}
+
+// WithDirections sets the direction set of the map, 4way by default
+func WithDirections(directions types.DirectionSet) Option {
+	return func(s *Simulation) {
+		s.directions = directions
+	}
+}
+
+// WithOutput sets the writer the events are reported to
+func WithOutput(out io.Writer) Option {
+	return func(s *Simulation) {
+		s.out = out
+	}
+}
+
+// WithRand sets the random source used by the simulation
+func WithRand(rng *rand.Rand) Option {
+	return func(s *Simulation) {
+		s.rng = rng
+	}
 }
 
-func NewSimulation(worldMap types.World, aliensCount, maxIterations int) (*Simulation, error) {
+func NewSimulation(worldMap types.World, aliensCount, maxIterations int, opts ...Option) (*Simulation, error) {
 	if len(worldMap) <= 0 {
 		return nil, ErrInvalidCityCount
 	}
 
-	// Assumption: 0 < Aliens_count <= 2*cities_count
-	if aliensCount <= 0 || aliensCount > 2*len(worldMap) {
+	if aliensCount <= 0 {
 		return nil, ErrInvalidAliensCount
 	}
 
-	return &Simulation{
-		count:         0,
-		worldMap:      worldMap,
-		maxIterations: maxIterations,
-		aliens:        make(map[int]*types.City, aliensCount),
-	}, nil
+	s := &Simulation{
+		count:          0,
+		worldMap:       worldMap,
+		maxIterations:  maxIterations,
+		aliens:         make(types.Aliens, aliensCount),
+		roster:         make([]*types.Alien, 0, aliensCount),
+		capacity:       DefaultCityCapacity,
+		placement:      Uniform{},
+		fightRule:      CoLocation{},
+		destroyedBy:    make(map[string]int),
+		defense:        DefaultDefenseModel,
+		mothershipRule: DefaultMothershipRule,
+		directions:     types.FourWay,
+		out:            os.Stdout,
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.capacity <= 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	if err := s.battle.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.defense.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.encounter.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.roadDamage.Validate(); err != nil {
+		return nil, err
+	}
+
+	if s.recovery < 0 {
+		return nil, ErrInvalidRecovery
+	}
+
+	if err := s.mothershipRule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.energy.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, city := range worldMap {
+		s.population += city.Population()
+	}
+
+	for _, base := range s.bases {
+		city := s.worldMap.GetCity(base.City)
+		if city == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCity, base.City)
+		}
+
+		if s.findMothership(city) == nil {
+			s.motherships = append(s.motherships, &mothership{city: city, faction: base.Faction})
+		}
+	}
+
+	return s, nil
 }
 
 // InitAliens allocates the aliens to random cities
@@ -47,140 +323,369 @@ func (s *Simulation) InitAliens(cities []*types.City, aliensCount int) error {
 		return ErrInvalidCityCount
 	}
 
-	// Assumption: 0 < Aliens_count <= 2*cities_count
-	if aliensCount <= 0 || aliensCount > 2*len(cities) {
+	// Assumption: 0 < Aliens_count <= capacity*cities_count
+	if aliensCount <= 0 || aliensCount > s.capacity*len(cities) {
 		return ErrInvalidAliensCount
 	}
 
-	for alienID := 0; alienID < aliensCount; {
-		city := pickRandomCity(cities)
+	if err := s.checkSpawnCities(); err != nil {
+		return err
+	}
+
+	if err := s.checkScenarioCities(); err != nil {
+		return err
+	}
+
+	if len(s.pinned) > aliensCount {
+		return fmt.Errorf("%w: %d pinned aliens for %d aliens", ErrInvalidPlacement, len(s.pinned), aliensCount)
+	}
+
+	for alienID, entry := range s.pinned {
+		city := s.worldMap.GetCity(entry.City)
 		if city == nil {
-			continue
+			return fmt.Errorf("%w: %s", ErrUnknownCity, entry.City)
 		}
 
-		if city.OccupiedAliens == nil {
-			city.OccupiedAliens = make(map[int]interface{})
+		if len(city.OccupiedAliens) >= s.capacity {
+			return fmt.Errorf("%w: %s is full", ErrPlacementFailed, entry.City)
 		}
 
-		// Only two aliens are allocated per city.
-		if len(city.OccupiedAliens) >= 2 {
-			continue
+		alien := s.newAlien(alienID, city)
+		if entry.Faction != "" {
+			alien.Faction = entry.Faction
 		}
 
-		city.AddAlien(alienID)
-		s.aliens.AddAlien(alienID, city)
-		alienID++
+		s.addAlien(alien)
+	}
+
+	placed, err := placeAliens(s.placement, cities, aliensCount-len(s.pinned), s.capacity, s.rng)
+	if err != nil {
+		return err
+	}
+
+	for i, city := range placed {
+		s.addAlien(s.newAlien(len(s.pinned)+i, city))
 	}
 
 	return nil
 }
 
+// newAlien creates an alien with its configured name and faction
+func (s *Simulation) newAlien(id int, city *types.City) *types.Alien {
+	alien := types.NewAlien(id, s.alienName(id), city)
+	alien.Energy = s.energy.Max
+	alien.Class = s.pickClass()
+
+	if alien.Class == types.Tank {
+		alien.Armor = 1
+	}
+
+	if id < len(s.factions) {
+		alien.Faction = s.factions[id]
+	}
+
+	return alien
+}
+
+// addAlien registers the alien in the simulation and its city
+func (s *Simulation) addAlien(alien *types.Alien) {
+	alien.City.AddAlien(alien)
+	s.aliens.AddAlien(alien)
+	s.roster = append(s.roster, alien)
+}
+
+// alienName returns the configured name of the alien, or a generated one. The generated
+// names already given to a configured alien are skipped so that every name stays unique.
+func (s *Simulation) alienName(id int) string {
+	if id < len(s.names) {
+		return s.names[id]
+	}
+
+	for len(s.generated) <= id-len(s.names) {
+		name := GenerateAlienName(len(s.names) + len(s.generated) + s.skippedNames)
+		if s.configured[name] {
+			s.skippedNames++
+
+			continue
+		}
+
+		s.generated = append(s.generated, name)
+	}
+
+	return s.generated[id-len(s.names)]
+}
+
+// Aliens returns every alien of the simulation, including the dead ones, ordered by Id
+func (s *Simulation) Aliens() []*types.Alien {
+	aliens := append([]*types.Alien(nil), s.roster...)
+	sort.Slice(aliens, func(i, j int) bool { return aliens[i].ID < aliens[j].ID })
+
+	return aliens
+}
+
+// Iterations returns the number of iterations run so far
+func (s *Simulation) Iterations() int {
+	return s.count
+}
+
+// Cities returns the standing cities in order of their names
+func (s *Simulation) Cities() []*types.City {
+	cities := make([]*types.City, 0, len(s.worldMap))
+
+	for _, name := range s.worldMap.Names() {
+		if city := s.worldMap.GetCity(name); s.standing(city) {
+			cities = append(cities, city)
+		}
+	}
+
+	return cities
+}
+
 // CanContinue checks
 func (s *Simulation) CanContinue() bool {
-	if s.count >= s.maxIterations || len(s.aliens) == 0 || len(s.worldMap) == 0 {
+	if s.count >= s.maxIterations || (len(s.aliens) == 0 && !s.spawnsPending() && !s.scenarioPending() && len(s.motherships) == 0) || len(s.worldMap) == 0 {
 		return false
 	}
 
 	return true
 }
 
-// Run starts the alien invasion
-func (s *Simulation) Run(closeCh chan os.Signal) {
+// Run starts the alien invasion, ErrStopped is returned when a signal stopped it
+func (s *Simulation) Run(closeCh chan os.Signal) error {
 	s.checkForFight()
 
-	defer func() { fmt.Println("Aliens left", len(s.aliens)) }()
+	defer func() { fmt.Fprintln(s.out, "Aliens left", len(s.aliens)) }()
 
 	for s.CanContinue() {
 		select {
 		case <-closeCh:
-			fmt.Println("*****************************************")
-			fmt.Println("Stopping the invasion")
-			fmt.Println("*****************************************")
+			fmt.Fprintln(s.out, "*****************************************")
+			fmt.Fprintln(s.out, "Stopping the invasion")
+			fmt.Fprintln(s.out, "*****************************************")
 
-			return
+			return ErrStopped
 		default:
+			s.rebuildCities()
+			s.applyScenario()
+			s.applyPolicies()
+			s.spawnAliens()
+			s.launchAliens()
+			s.strikeMotherships()
 			s.moveAliens()
 			s.count++
 		}
 	}
+
+	return nil
 }
 
-// cleanupAliens removes the aliens from the alien map
-func (s *Simulation) cleanupAliens(aliens map[int]interface{}) []int {
-	keys := make([]int, 0, len(aliens))
-	for alien := range aliens {
-		keys = append(keys, alien)
-		s.aliens.DeleteAlien(alien)
+// cleanupAliens marks the aliens as dead and removes them from the alien map
+func (s *Simulation) cleanupAliens(aliens map[int]*types.Alien) []*types.Alien {
+	dead := sortedAliens(aliens)
+	for _, alien := range dead {
+		alien.Status = types.Dead
+		s.aliens.DeleteAlien(alien.ID)
 	}
 
-	return keys
+	return dead
 }
 
-// checkForFight checks for a fight between aliens, in case of a fight the city will be destroyed
-func (s *Simulation) checkForFight() {
-	for _, currentCity := range s.aliens {
-		if len(currentCity.OccupiedAliens) > 1 {
-			s.distroyCity(currentCity)
+// killAliens marks the aliens as dead and removes them from the alien map and their city
+func (s *Simulation) killAliens(aliens []*types.Alien) []*types.Alien {
+	for _, alien := range aliens {
+		alien.Status = types.Dead
+		s.aliens.DeleteAlien(alien.ID)
+
+		if alien.City != nil {
+			alien.City.RemoveAlien(alien.ID)
+		}
+	}
+
+	return aliens
+}
 
+// checkForFight checks for a fight between aliens, in case of a fight the battle is resolved
+func (s *Simulation) checkForFight() {
+	for _, id := range s.aliens.IDs() {
+		alien := s.aliens.GetAlien(id)
+		if alien == nil || alien.OnRoad() {
+			// Alien died in an earlier fight or is travelling
 			continue
 		}
+
+		if s.shouldFight(alien.City.OccupiedAliens) {
+			s.resolveFight(alien.City)
+		}
 	}
 }
 
-// moveAliens picks a random neighbour and moves the alien, in case of a fight the city is destroyed
+// moveAliens moves every alien by one tick, in case of a fight the battle is resolved
 func (s *Simulation) moveAliens() {
-	for alien, currentCity := range s.aliens {
-		// Get random neighbour
-		newCity, err := currentCity.PickRandomNeighbours()
-		if err != nil {
-			// Alien is trapped
+	if s.simultaneous {
+		s.moveAliensSimultaneously()
+
+		return
+	}
+
+	s.moveAliensSequentially()
+
+	if s.encounter.Outcome != EncounterNone {
+		s.checkForRoadFights()
+	}
+}
+
+// moveAliensSequentially moves the aliens one after the other in order of their Id
+func (s *Simulation) moveAliensSequentially() {
+	for _, id := range s.aliens.IDs() {
+		alien := s.aliens.GetAlien(id)
+		if alien == nil {
+			// Alien died earlier in this iteration
 			continue
 		}
 
-		// Move the alien and update occupancy
-		newCity.AddAlien(alien)
-		s.aliens.AddAlien(alien, newCity)
-		delete(s.worldMap[currentCity.Name].OccupiedAliens, alien)
-
-		// If an alien exists in the chosen city, than city can be destroyed in the same iteration.
-		if len(newCity.OccupiedAliens) > 1 {
-			s.distroyCity(newCity)
+		if alien.OnRoad() {
+			s.travel(alien)
 
 			continue
 		}
+
+		moves := alien.Moves
+		s.moveAlien(alien)
+		// Scouts take a second hop once they reach a city
+		if alien.Class == types.Scout && alien.Moves > moves && alien.Status != types.Dead && !alien.OnRoad() {
+			s.moveAlien(alien)
+		}
+	}
+}
+
+// moveAlien picks a random neighbour and moves the alien, long roads take several ticks
+func (s *Simulation) moveAlien(alien *types.Alien) {
+	if s.rests(alien) {
+		return
+	}
+
+	currentCity := alien.City
+	// Get random neighbour
+	direction, err := currentCity.PickRandomDirection(s.rng)
+	if err != nil {
+		// Alien is trapped
+		alien.Status = types.Trapped
+		s.recoverEnergy(alien)
+
+		return
+	}
+
+	if s.exhaust(alien, currentCity, direction) {
+		return
+	}
+
+	newCity, length := currentCity.Neighbours[direction], currentCity.RoadLength(direction)
+	// The road may collapse behind the alien
+	s.damageRoad(alien, currentCity, direction)
+
+	if length > 1 {
+		currentCity.RemoveAlien(alien.ID)
+		alien.Depart(direction, newCity, length-1)
+
+		return
+	}
+
+	s.arrive(alien, newCity)
+}
+
+// arrive moves the alien into the city
+func (s *Simulation) arrive(alien *types.Alien, city *types.City) {
+	// Move the alien and update occupancy
+	if alien.City != nil {
+		alien.City.RemoveAlien(alien.ID)
+	}
+
+	alien.MoveTo(city)
+	city.AddAlien(alien)
+
+	// The city defenses may kill the alien before it can fight
+	if s.repelAlien(city, alien) {
+		return
+	}
+
+	// Rival aliens entering a mothership assault it instead of fighting
+	if s.assaultMothership(city, alien) || s.bomb(city, alien) {
+		return
+	}
+
+	// If a rival alien exists in the chosen city, than the battle happens in the same iteration.
+	if s.shouldFight(city.OccupiedAliens) {
+		s.resolveFight(city)
 	}
 }
 
 // distroyCity deletes the city and associated roads,aliens
 func (s *Simulation) distroyCity(city *types.City) {
+	s.fallen = append(s.fallen, fallenCity{city: city, neighbours: neighbourhood(city)})
+	if len(s.scenario) > 0 {
+		s.scenarioFallen = append(s.scenarioFallen, s.fallen[len(s.fallen)-1])
+	}
+	// Keep the roads of the city for its rebuild
+	if s.recovery > 0 {
+		s.ruins = append(s.ruins, &ruin{city: city, destroyedAt: s.count, roads: snapshotRoads(city)})
+	}
 	// Cleanup the linking roads
 	s.cleanupRoads(city)
 	// Delete the aliens
 	aliens := s.cleanupAliens(city.OccupiedAliens)
-	// Delete the city from world map
-	s.worldMap.DeleteCity(city.Name)
-	fmt.Printf("%s has been destroyed by alien %d and alien %d ! \n", city.Name, aliens[0], aliens[1])
+	// Credit the destruction to every faction involved
+	factions := make(map[string]bool)
+	for _, alien := range aliens {
+		factions[alien.Faction] = true
+	}
+
+	for faction := range factions {
+		s.destroyedBy[faction]++
+	}
+	s.removeMothership(city)
+	// Delete the city from world map, unless it is left in ruins
+	if s.recovery > 0 {
+		city.State = types.Ruins
+		city.OccupiedAliens = nil
+	} else {
+		s.worldMap.DeleteCity(city.Name)
+	}
+
+	s.emit(Event{Kind: EventCityDestroyed, City: city.Name, Aliens: alienNames(aliens)})
 }
 
 // cleanupRoads removes all the inward/outward links
 func (s *Simulation) cleanupRoads(c *types.City) {
-	c.Neighbours = nil
+	s.worldMap.RemoveCityRoads(c.Name) //nolint:errcheck // the city is part of the world
+}
 
-	for _, city := range s.worldMap {
-		for direction, neighbourCity := range city.Neighbours {
-			if neighbourCity != nil && neighbourCity.Name == c.Name {
-				city.Neighbours[direction] = nil
-			}
-		}
+// sortedAliens returns the aliens ordered by Id
+func sortedAliens(aliens map[int]*types.Alien) []*types.Alien {
+	sorted := make([]*types.Alien, 0, len(aliens))
+	for _, alien := range aliens {
+		sorted = append(sorted, alien)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	return sorted
+}
+
+// alienNames returns the names of the aliens
+func alienNames(aliens []*types.Alien) []string {
+	names := make([]string, 0, len(aliens))
+	for _, alien := range aliens {
+		names = append(names, alien.String())
 	}
+
+	return names
 }
 
-// pickRandomCity chooses a random city from the given set of cities
-func pickRandomCity(cities []*types.City) *types.City {
-	randomBigInt, err := rand.Int(rand.Reader, big.NewInt(int64(len(cities))))
-	if err != nil {
-		return nil
+// joinNames returns a human readable enumeration of the names
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
 	}
 
-	return cities[randomBigInt.Int64()]
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
 }
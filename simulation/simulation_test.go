@@ -135,18 +135,17 @@ func TestCleanupAliens(t *testing.T) {
 	require.NoError(t, err)
 	// create test aliens
 	testAliens := createTestAliens(2, cities)
-	alienIds := make(map[int]interface{})
 
-	for id, city := range testAliens {
-		simulation.aliens.AddAlien(id, city)
-
-		alienIds[id] = nil
+	for _, alien := range testAliens {
+		simulation.aliens.AddAlien(alien)
 	}
 	// cleanup aliens
-	simulation.cleanupAliens(alienIds)
+	dead := simulation.cleanupAliens(testAliens)
+	require.Len(t, dead, 2)
 	// verify
-	for id := range testAliens {
+	for id, alien := range testAliens {
 		require.Nil(t, simulation.aliens.GetAlien(id))
+		require.Equal(t, types.Dead, alien.Status)
 	}
 }
 
@@ -164,7 +163,7 @@ func TestCleanupRoads(t *testing.T) {
 	simulation, err := NewSimulation(testWorld, 4, 0)
 	require.NoError(t, err)
 
-	randomCity := pickRandomCity(cities)
+	randomCity := cities[simulation.rng.Intn(len(cities))]
 	simulation.cleanupRoads(randomCity)
 
 	for _, city := range testWorld {
@@ -193,7 +192,7 @@ func createTestWorldWithNeighbours(citiesCount int, neighbours [][]int) (types.W
 	for j := 0; j < citiesCount; j++ {
 		neighbour := neighbours[j]
 		for k := 0; k < len(neighbour); k++ {
-			cities[j].Neighbours[types.Direction(k)] = cities[neighbour[k]]
+			cities[j].SetNeighbour(types.Direction(k), cities[neighbour[k]])
 		}
 		world.AddCity(cities[j]) //nolint
 	}
@@ -201,13 +200,13 @@ func createTestWorldWithNeighbours(citiesCount int, neighbours [][]int) (types.W
 	return world, cities, nil
 }
 
-func createTestAliens(count int, city []*types.City) map[int]*types.City {
+func createTestAliens(count int, city []*types.City) map[int]*types.Alien {
 	rand.Seed(time.Now().UnixNano())
 
-	aliens := make(map[int]*types.City, count)
+	aliens := make(map[int]*types.Alien, count)
 
 	for i := 0; i < count; i++ {
-		aliens[i] = city[rand.Intn(len(city))] //nolint
+		aliens[i] = types.NewAlien(i, "", city[rand.Intn(len(city))]) //nolint
 	}
 
 	return aliens
@@ -231,7 +230,113 @@ func createTestWorld(citiesCount int) (types.World, []*types.City) {
 
 func createTestSimulation(world types.World, aliensCount int) *Simulation {
 	return &Simulation{
-		worldMap: world,
-		aliens:   make(map[int]*types.City, aliensCount),
+		worldMap:    world,
+		aliens:      make(types.Aliens, aliensCount),
+		capacity:    DefaultCityCapacity,
+		placement:   Uniform{},
+		fightRule:   CoLocation{},
+		destroyedBy: make(map[string]int),
+		defense:     DefaultDefenseModel,
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	}
+}
+
+func TestMoveAliensRecordsHistory(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(3, [][]int{
+		{1},
+		{2},
+		{},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10)
+	require.NoError(t, err)
+	simulation.addAlien(types.NewAlien(0, "Zorg", cities[0]))
+
+	simulation.moveAliens()
+	simulation.moveAliens()
+	simulation.moveAliens()
+
+	alien := simulation.aliens.GetAlien(0)
+	require.Equal(t, []string{"testCity_0", "testCity_1", "testCity_2"}, alien.Path)
+	require.Equal(t, 2, alien.Moves)
+	require.Equal(t, types.Trapped, alien.Status)
+	require.Equal(t, cities[0], alien.Spawn)
+	require.Equal(t, "Zorg", alien.String())
+}
+
+func TestDistroyCityKillsAliens(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 2, 10)
+	require.NoError(t, err)
+	simulation.addAlien(types.NewAlien(0, "", cities[0]))
+	simulation.addAlien(types.NewAlien(1, "", cities[0]))
+
+	simulation.checkForFight()
+
+	require.Nil(t, testWorld.GetCity("testCity_0"))
+	require.Empty(t, simulation.aliens)
+
+	for _, alien := range simulation.Aliens() {
+		require.Equal(t, types.Dead, alien.Status)
 	}
 }
+
+func TestInitAliens_Names(t *testing.T) {
+	testWorld, cities := createTestWorld(5)
+
+	simulation, err := NewSimulation(testWorld, 3, 0, WithAlienNames([]string{"Zorg", "Blip"}))
+	require.NoError(t, err)
+	require.NoError(t, simulation.InitAliens(cities, 3))
+
+	aliens := simulation.Aliens()
+	require.Equal(t, "Zorg", aliens[0].String())
+	require.Equal(t, "Blip", aliens[1].String())
+	require.Equal(t, GenerateAlienName(2), aliens[2].String())
+}
+
+func TestInitAliens_GeneratedNamesSkipConfiguredOnes(t *testing.T) {
+	testWorld, cities := createTestWorld(5)
+	// The name generated for alien 2 is already given to alien 1
+	simulation, err := NewSimulation(testWorld, 4, 0, WithAlienNames([]string{"Zorg", GenerateAlienName(2)}))
+	require.NoError(t, err)
+	require.NoError(t, simulation.InitAliens(cities, 4))
+
+	aliens := simulation.Aliens()
+	require.Equal(t, GenerateAlienName(2), aliens[1].String())
+	require.Equal(t, GenerateAlienName(3), aliens[2].String())
+	require.Equal(t, GenerateAlienName(4), aliens[3].String())
+}
+
+func TestPickRandomNeighboursFollowsRoadWeights(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(3, [][]int{
+		{1, 2},
+		{0},
+		{0},
+	})
+	require.NoError(t, err)
+	// The highway to the north is taken 99 times out of 100
+	cities[0].SetRoadWeight(types.North, 99)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithRand(rand.New(rand.NewSource(1)))) //nolint:gosec
+	require.NoError(t, err)
+
+	north := 0
+
+	for i := 0; i < 1000; i++ {
+		neighbour, err := cities[0].PickRandomNeighbours(simulation.rng)
+		require.NoError(t, err)
+
+		if neighbour == cities[1] {
+			north++
+		}
+	}
+
+	require.Greater(t, north, 950)
+	require.Less(t, north, 1000)
+}
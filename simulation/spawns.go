@@ -0,0 +1,284 @@
+package simulation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+var ErrInvalidSpawn = errors.New("invalid spawn")
+
+const (
+	scheduleAt    = "at"
+	scheduleEvery = "every"
+	// spawnSeparator separates the rules of a spawn spec given on the command line
+	spawnSeparator = ";"
+)
+
+// SpawnRule lands reinforcements during the invasion, either once at a given
+// iteration or every given number of iterations.
+type SpawnRule struct {
+	At    int
+	Every int
+	Count int
+	// City is the landing city, the aliens land in random cities when empty
+	City    string
+	Faction string
+}
+
+// due checks whether the rule spawns aliens at the given iteration
+func (r SpawnRule) due(iteration int) bool {
+	if r.Every > 0 {
+		return iteration > 0 && iteration%r.Every == 0
+	}
+
+	return iteration == r.At
+}
+
+// ParseSpawnSpec parses the spawn rules separated by ';', e.g.
+// "every 100: spawn 5; at 500: spawn 10 at Berlin faction=red".
+func ParseSpawnSpec(spec string) ([]SpawnRule, error) {
+	rules := make([]SpawnRule, 0)
+
+	for _, entry := range strings.Split(spec, spawnSeparator) {
+		tokens, err := tokenize(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, entry)
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		rule, err := parseSpawnRule(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, entry)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// LoadSpawnFile reads one spawn rule per line, blank lines and '#' comments are ignored
+func LoadSpawnFile(filePath string) ([]SpawnRule, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	defer file.Close()
+
+	rules := make([]SpawnRule, 0)
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+
+		tokens, err := tokenize(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filePath, lineNumber, err)
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		rule, err := parseSpawnRule(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filePath, lineNumber, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// parseSpawnRule parses a "<at|every> <n>: spawn <count> [at <city>] [faction=<name>]" rule
+func parseSpawnRule(tokens []token) (SpawnRule, error) {
+	rule := SpawnRule{}
+
+	rest, err := parseSchedule(tokens, &rule.At, &rule.Every)
+	if err != nil {
+		return SpawnRule{}, err
+	}
+
+	if len(rest) == 0 || rest[0].hasValue || rest[0].key != "spawn" {
+		return SpawnRule{}, ErrInvalidSpawn
+	}
+
+	if rule.Count, rule.City, rule.Faction, err = parseSpawn(rest[1:]); err != nil {
+		return SpawnRule{}, err
+	}
+
+	return rule, nil
+}
+
+// parseSchedule parses the leading "at <n>:" or "every <n>:" tokens and returns the tokens left
+func parseSchedule(tokens []token, at, every *int) ([]token, error) {
+	if len(tokens) < 2 || tokens[0].hasValue || tokens[1].hasValue || !strings.HasSuffix(tokens[1].key, ":") {
+		return nil, ErrInvalidSpawn
+	}
+
+	iteration, err := strconv.Atoi(strings.TrimSuffix(tokens[1].key, ":"))
+	if err != nil || iteration < 0 {
+		return nil, ErrInvalidSpawn
+	}
+
+	switch tokens[0].key {
+	case scheduleAt:
+		*at = iteration
+	case scheduleEvery:
+		if iteration == 0 {
+			return nil, ErrInvalidSpawn
+		}
+
+		*every = iteration
+	default:
+		return nil, ErrInvalidSpawn
+	}
+
+	return tokens[2:], nil
+}
+
+// parseSpawn parses the "<count> [at <city>] [faction=<name>]" arguments of a spawn
+func parseSpawn(tokens []token) (int, string, string, error) {
+	if len(tokens) == 0 || tokens[0].hasValue {
+		return 0, "", "", ErrInvalidSpawn
+	}
+
+	count, err := strconv.Atoi(tokens[0].key)
+	if err != nil || count <= 0 {
+		return 0, "", "", ErrInvalidSpawn
+	}
+
+	city, faction := "", ""
+
+	for i := 1; i < len(tokens); i++ {
+		switch {
+		case !tokens[i].hasValue && tokens[i].key == scheduleAt && i+1 < len(tokens) && !tokens[i+1].hasValue:
+			city = tokens[i+1].key
+			i++
+		case tokens[i].hasValue && tokens[i].key == "faction" && tokens[i].value != "":
+			faction = tokens[i].fullValue()
+		default:
+			return 0, "", "", ErrInvalidSpawn
+		}
+	}
+
+	return count, city, faction, nil
+}
+
+// checkSpawnCities checks that the landing cities of the spawn rules exist
+func (s *Simulation) checkSpawnCities() error {
+	for _, rule := range s.spawns {
+		if rule.City != "" && s.worldMap.GetCity(rule.City) == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownCity, rule.City)
+		}
+	}
+
+	return nil
+}
+
+// spawnsPending checks whether any spawn rule can still land aliens
+func (s *Simulation) spawnsPending() bool {
+	for _, rule := range s.spawns {
+		if rule.Every > 0 || rule.At >= s.count {
+			return true
+		}
+	}
+
+	return false
+}
+
+// spawnAliens lands the reinforcements due at the current iteration
+func (s *Simulation) spawnAliens() {
+	for _, rule := range s.spawns {
+		if rule.due(s.count) {
+			s.spawn(rule.Count, rule.City, rule.Faction)
+		}
+	}
+}
+
+// spawn lands count aliens of the faction in the city, or in random cities when empty,
+// within the capacity of the cities. The new aliens fight the rivals they land with.
+func (s *Simulation) spawn(count int, landing, faction string) {
+	cities := s.landingCities(landing)
+	free := make([]int, len(cities))
+	weights := make([]float64, len(cities))
+
+	for i, city := range cities {
+		free[i] = s.capacity - len(city.OccupiedAliens)
+		weights[i] = 1
+	}
+
+	landed := make([]*types.City, 0)
+	spawned := make(map[*types.City][]*types.Alien)
+
+	for n := 0; n < count; n++ {
+		i := pickWeighted(weights, free, s.rng)
+		if i < 0 {
+			s.emit(Event{Kind: EventSpawnBlocked, City: landing, Count: count - n})
+
+			break
+		}
+
+		free[i]--
+		city := cities[i]
+
+		alien := s.newAlien(len(s.roster), city)
+		if faction != "" {
+			alien.Faction = faction
+		}
+
+		s.addAlien(alien)
+
+		if len(spawned[city]) == 0 {
+			landed = append(landed, city)
+		}
+
+		spawned[city] = append(spawned[city], alien)
+	}
+
+	for _, city := range landed {
+		s.emit(Event{Kind: EventSpawn, City: city.Name, Aliens: alienNames(spawned[city])})
+	}
+
+	for _, city := range landed {
+		if s.standing(city) && s.shouldFight(city.OccupiedAliens) {
+			s.resolveFight(city)
+		}
+	}
+}
+
+// landingCities returns the standing cities aliens can land in, every one of them when name is empty
+func (s *Simulation) landingCities(name string) []*types.City {
+	if name != "" {
+		if city := s.worldMap.GetCity(name); city != nil && s.standing(city) {
+			return []*types.City{city}
+		}
+
+		return nil
+	}
+
+	cities := make([]*types.City, 0, len(s.worldMap))
+
+	for _, name := range s.worldMap.Names() {
+		if city := s.worldMap.GetCity(name); s.standing(city) {
+			cities = append(cities, city)
+		}
+	}
+
+	return cities
+}
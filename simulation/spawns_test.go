@@ -0,0 +1,121 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpawnSpec(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     string
+		expected []SpawnRule
+		err      error
+	}{
+		{name: "empty", spec: "", expected: []SpawnRule{}},
+		{name: "every", spec: "every 100: spawn 5", expected: []SpawnRule{{Every: 100, Count: 5}}},
+		{
+			name: "several rules",
+			spec: `every 100: spawn 5; at 500: spawn 10 at "New York" faction=red`,
+			expected: []SpawnRule{
+				{Every: 100, Count: 5},
+				{At: 500, Count: 10, City: "New York", Faction: "red"},
+			},
+		},
+		{name: "missing colon", spec: "at 5 spawn 1", err: ErrInvalidSpawn},
+		{name: "unknown schedule", spec: "after 5: spawn 1", err: ErrInvalidSpawn},
+		{name: "zero interval", spec: "every 0: spawn 1", err: ErrInvalidSpawn},
+		{name: "invalid count", spec: "at 5: spawn none", err: ErrInvalidSpawn},
+		{name: "missing city", spec: "at 5: spawn 1 at", err: ErrInvalidSpawn},
+		{name: "unknown argument", spec: "at 5: spawn 1 near Bonn", err: ErrInvalidSpawn},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := ParseSpawnSpec(tc.spec)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rules)
+		})
+	}
+}
+
+func TestLoadSpawnFile(t *testing.T) {
+	_, fileName := createTempFile(t)
+	err := os.WriteFile(fileName, []byte("# reinforcements\n\nevery 10: spawn 2\nat 3: spawn 1 at Bonn # pinned\n"), 0600)
+	require.NoError(t, err)
+
+	rules, err := LoadSpawnFile(fileName)
+	require.NoError(t, err)
+	require.Equal(t, []SpawnRule{{Every: 10, Count: 2}, {At: 3, Count: 1, City: "Bonn"}}, rules)
+}
+
+func TestSpawnAliens(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{0},
+	})
+	require.NoError(t, err)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithCapacity(2), WithOutput(ioutil.Discard),
+		WithFightRule(RivalFactions{}), WithSpawns([]SpawnRule{
+			{At: 2, Count: 3, City: "testCity_1", Faction: "red"},
+			{Every: 4, Count: 5},
+		}))
+	require.NoError(t, err)
+	require.NoError(t, simulation.InitAliens(cities[:1], 1))
+
+	simulation.count = 2
+	simulation.spawnAliens()
+	// Only two aliens fit in the city, their Ids follow the initial allocation
+	require.Equal(t, []int{0, 1, 2}, simulation.aliens.IDs())
+	require.Len(t, cities[1].OccupiedAliens, 2)
+	require.Equal(t, "red", simulation.aliens.GetAlien(1).Faction)
+
+	events := simulation.Events()
+	require.Equal(t, EventSpawnBlocked, events[0].Kind)
+	require.Equal(t, 1, events[0].Count)
+	require.Equal(t, EventSpawn, events[1].Kind)
+	require.Len(t, events[1].Aliens, 2)
+
+	simulation.count = 3
+	simulation.spawnAliens()
+	require.Len(t, simulation.aliens, 3)
+
+	simulation.count = 4
+	simulation.spawnAliens()
+	require.Equal(t, []int{0, 1, 2, 3}, simulation.aliens.IDs())
+	require.Len(t, cities[0].OccupiedAliens, 2)
+}
+
+func TestSpawnsKeepInvasionGoing(t *testing.T) {
+	testWorld, cities := createTestWorld(2)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard),
+		WithSpawns([]SpawnRule{{At: 5, Count: 1}}))
+	require.NoError(t, err)
+	require.NoError(t, simulation.InitAliens(cities, 1))
+
+	simulation.killAliens(simulation.Aliens())
+	require.True(t, simulation.CanContinue())
+
+	simulation.count = 6
+	require.False(t, simulation.CanContinue())
+}
+
+func TestSpawnUnknownCity(t *testing.T) {
+	testWorld, cities := createTestWorld(2)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard),
+		WithSpawns([]SpawnRule{{At: 1, Count: 1, City: "nowhere"}}))
+	require.NoError(t, err)
+	require.ErrorIs(t, simulation.InitAliens(cities, 1), ErrUnknownCity)
+}
@@ -0,0 +1,116 @@
+package simulation
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// token is a whitespace separated field of a map line, split at its first unquoted '='.
+// The value is followed by the parameters separated by unquoted ':', e.g. north=Bonn:3.
+// Quoted parts, e.g. "New York", keep their whitespace, '=', ':' and '#' and support
+// the Go escape sequences.
+type token struct {
+	key      string
+	value    string
+	params   []string
+	hasValue bool
+	// quoted reports whether the key was quoted, a quoted key is never a directive nor an attribute
+	quoted bool
+}
+
+// tokenize splits the line into tokens, an unquoted '#' starts a comment
+func tokenize(line string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(line)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+
+			continue
+		}
+
+		if string(runes[i]) == commentPrefix {
+			break
+		}
+
+		var (
+			current token
+			part    strings.Builder
+			fields  []string
+		)
+
+		for ; i < len(runes) && !unicode.IsSpace(runes[i]) && string(runes[i]) != commentPrefix; i++ {
+			switch {
+			case runes[i] == '"':
+				end := closingQuote(runes, i)
+				if end < 0 {
+					return nil, ErrUnterminatedQuote
+				}
+
+				unquoted, err := strconv.Unquote(string(runes[i : end+1]))
+				if err != nil {
+					return nil, errors.Wrap(ErrInvalidLine, err.Error())
+				}
+
+				part.WriteString(unquoted)
+
+				if !current.hasValue {
+					current.quoted = true
+				}
+
+				i = end
+			case runes[i] == '=' && current.hasValue:
+				// A second separator is only allowed within quotes
+				return nil, errors.Wrap(ErrInvalidLine, "unquoted '=' in value")
+			case runes[i] == '=':
+				current.key, current.hasValue = part.String(), true
+				part.Reset()
+			case runes[i] == ':' && current.hasValue:
+				fields = append(fields, part.String())
+				part.Reset()
+			default:
+				part.WriteRune(runes[i])
+			}
+		}
+
+		if current.hasValue {
+			fields = append(fields, part.String())
+			current.value = fields[0]
+
+			if len(fields) > 1 {
+				current.params = fields[1:]
+			}
+		} else {
+			current.key = part.String()
+		}
+
+		tokens = append(tokens, current)
+	}
+
+	return tokens, nil
+}
+
+// closingQuote returns the index of the quote closing the one at start, -1 if there is none
+func closingQuote(runes []rune, start int) int {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// fullValue returns the value along with its parameters, as written
+func (t token) fullValue() string {
+	return strings.Join(append([]string{t.value}, t.params...), ":")
+}
@@ -0,0 +1,68 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		shouldFail bool
+		expected   []token
+	}{
+		{
+			name: "Bare tokens",
+			line: "Paris\tnorth=Lyon  # comment",
+			expected: []token{
+				{key: "Paris"},
+				{key: "north", value: "Lyon", hasValue: true},
+			},
+		},
+		{
+			name: "Quoted names",
+			line: `"New York" north="Jersey City" east="a=b # c"`,
+			expected: []token{
+				{key: "New York", quoted: true},
+				{key: "north", value: "Jersey City", hasValue: true},
+				{key: "east", value: "a=b # c", hasValue: true},
+			},
+		},
+		{
+			name: "Escape sequences",
+			line: `"Say \"Hi\"" west="Café\\"`,
+			expected: []token{
+				{key: `Say "Hi"`, quoted: true},
+				{key: "west", value: `Café\`, hasValue: true},
+			},
+		},
+		{
+			name: "Parameters",
+			line: `Paris north=Bonn:3 south="Le:Mans":2:1`,
+			expected: []token{
+				{key: "Paris"},
+				{key: "north", value: "Bonn", params: []string{"3"}, hasValue: true},
+				{key: "south", value: "Le:Mans", params: []string{"2", "1"}, hasValue: true},
+			},
+		},
+		{name: "Empty line", line: "   # only a comment", expected: []token{}},
+		{name: "Unterminated quote", line: `"New York north=Boston`, shouldFail: true},
+		{name: "Unquoted separator in value", line: `Paris north=a=b`, shouldFail: true},
+		{name: "Invalid escape", line: `"New\qYork" north=Boston`, shouldFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenize(tt.line)
+			if tt.shouldFail {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, tokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"sort"
+
+	"github.com/munna0908/alien-invasion/types"
+)
+
+// travel advances the alien on its road, the alien arrives at the end of the road
+func (s *Simulation) travel(alien *types.Alien) {
+	transit := alien.Transit
+	if transit.Remaining > 0 {
+		transit.Remaining--
+	}
+
+	if transit.Remaining > 0 {
+		return
+	}
+
+	if s.standing(transit.To) {
+		s.arrive(alien, transit.To)
+
+		return
+	}
+	// The destination was destroyed on the way, the alien heads back
+	if s.standing(transit.From) {
+		s.arrive(alien, transit.From)
+
+		return
+	}
+	// Nowhere to go, the alien is stranded on the road
+	alien.Status = types.Trapped
+}
+
+// checkForRoadFights settles the encounters of the aliens travelling the same road in opposite directions
+func (s *Simulation) checkForRoadFights() {
+	roads := make(map[[2]string]map[int]*types.Alien)
+
+	for _, id := range s.aliens.IDs() {
+		alien := s.aliens.GetAlien(id)
+		if !alien.OnRoad() {
+			continue
+		}
+
+		key := roadKey(alien.Transit)
+		if roads[key] == nil {
+			roads[key] = make(map[int]*types.Alien)
+		}
+
+		roads[key][id] = alien
+	}
+
+	keys := make([][2]string, 0, len(roads))
+	for key := range roads {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || (keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1])
+	})
+
+	for _, key := range keys {
+		aliens := sortedAliens(roads[key])
+		if headOn(roads[key]) {
+			s.resolveEncounter(aliens, aliens[0].Transit.From, aliens[0].Transit.To)
+		}
+	}
+}
+
+// roadKey identifies the road between two cities whatever the direction of travel
+func roadKey(transit *types.Transit) [2]string {
+	if transit.From.Name < transit.To.Name {
+		return [2]string{transit.From.Name, transit.To.Name}
+	}
+
+	return [2]string{transit.To.Name, transit.From.Name}
+}
+
+// headOn checks whether some of the aliens travel in opposite directions
+func headOn(aliens map[int]*types.Alien) bool {
+	var destination *types.City
+
+	for _, alien := range aliens {
+		if destination == nil {
+			destination = alien.Transit.To
+		} else if alien.Transit.To != destination {
+			return true
+		}
+	}
+
+	return false
+}
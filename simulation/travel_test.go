@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/munna0908/alien-invasion/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTravelOnLongRoads(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{},
+	})
+	require.NoError(t, err)
+	cities[0].SetRoadLength(types.North, 3)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+
+	alien := types.NewAlien(0, "", cities[0])
+	simulation.addAlien(alien)
+
+	simulation.moveAliens()
+	require.True(t, alien.OnRoad())
+	require.Empty(t, cities[0].OccupiedAliens)
+	require.Equal(t, "on the road from testCity_0 to testCity_1", alien.Position())
+
+	simulation.moveAliens()
+	require.True(t, alien.OnRoad())
+
+	simulation.moveAliens()
+	require.False(t, alien.OnRoad())
+	require.Equal(t, cities[1], alien.City)
+	require.Contains(t, cities[1].OccupiedAliens, 0)
+	require.Equal(t, 1, alien.Moves)
+}
+
+func TestTravelToDestroyedCity(t *testing.T) {
+	testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+		{1},
+		{},
+	})
+	require.NoError(t, err)
+	cities[0].SetRoadLength(types.North, 2)
+
+	simulation, err := NewSimulation(testWorld, 1, 10, WithOutput(ioutil.Discard))
+	require.NoError(t, err)
+
+	alien := types.NewAlien(0, "", cities[0])
+	simulation.addAlien(alien)
+
+	simulation.moveAliens()
+	require.True(t, alien.OnRoad())
+	// The destination is destroyed while the alien travels
+	simulation.distroyCity(cities[1])
+
+	simulation.moveAliens()
+	require.False(t, alien.OnRoad())
+	require.Equal(t, cities[0], alien.City)
+}
+
+func TestRoadFights(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+			{1},
+			{0},
+		})
+		require.NoError(t, err)
+		cities[0].SetRoadLength(types.North, 4)
+		cities[1].SetRoadLength(types.North, 4)
+
+		simulation, err := NewSimulation(testWorld, 2, 10, WithRoadFights(enabled), WithOutput(ioutil.Discard))
+		require.NoError(t, err)
+		simulation.addAlien(types.NewAlien(0, "", cities[0]))
+		simulation.addAlien(types.NewAlien(1, "", cities[1]))
+
+		simulation.moveAliens()
+
+		if enabled {
+			require.Empty(t, simulation.aliens)
+
+			events := simulation.Events()
+			require.Len(t, events, 1)
+			require.Equal(t, EventRoadFight, events[0].Kind)
+			require.Equal(t, "testCity_0", events[0].From)
+			require.Equal(t, "testCity_1", events[0].To)
+		} else {
+			require.Len(t, simulation.aliens, 2)
+			require.Empty(t, simulation.Events())
+		}
+	}
+}
+
+func TestSimultaneousSwap(t *testing.T) {
+	testCases := []struct {
+		name         string
+		opts         []Option
+		aliens       int
+		destroyed    bool
+		expectedKind EventKind
+	}{
+		{name: "no encounter rule", opts: []Option{WithSimultaneousMoves(true)}, aliens: 2},
+		{
+			name:   "fight",
+			opts:   []Option{WithSimultaneousMoves(true), WithRoadEncounter(RoadEncounter{Outcome: EncounterFight})},
+			aliens: 0, expectedKind: EventRoadFight,
+		},
+		{
+			name:   "duel",
+			opts:   []Option{WithSimultaneousMoves(true), WithRoadEncounter(RoadEncounter{Outcome: EncounterDuel})},
+			aliens: 1, expectedKind: EventRoadDuel,
+		},
+		{
+			name: "road destroyed",
+			opts: []Option{
+				WithSimultaneousMoves(true),
+				WithRoadEncounter(RoadEncounter{Outcome: EncounterDuel, DestroyRoad: 1}),
+			},
+			aliens: 1, destroyed: true, expectedKind: EventRoadDuel,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testWorld, cities, err := createTestWorldWithNeighbours(2, [][]int{
+				{1},
+				{0},
+			})
+			require.NoError(t, err)
+
+			opts := append([]Option{WithOutput(ioutil.Discard)}, tc.opts...)
+			simulation, err := NewSimulation(testWorld, 2, 10, opts...)
+			require.NoError(t, err)
+
+			first, second := types.NewAlien(0, "", cities[0]), types.NewAlien(1, "", cities[1])
+			simulation.addAlien(first)
+			simulation.addAlien(second)
+
+			simulation.moveAliens()
+			require.Len(t, simulation.aliens, tc.aliens)
+
+			if tc.aliens == 2 {
+				// The aliens pass each other
+				require.Empty(t, simulation.Events())
+				require.Equal(t, cities[1], first.City)
+				require.Equal(t, cities[0], second.City)
+
+				return
+			}
+
+			events := simulation.Events()
+			require.Equal(t, tc.expectedKind, events[0].Kind)
+
+			if tc.destroyed {
+				require.Len(t, events, 2)
+				require.Equal(t, EventRoadDestroyed, events[1].Kind)
+				require.Nil(t, cities[0].Neighbours[types.North])
+				require.Nil(t, cities[1].Neighbours[types.North])
+			}
+			// The survivor carries on its way
+			for _, alien := range simulation.aliens {
+				require.NotEqual(t, alien.Spawn, alien.City)
+			}
+		})
+	}
+}
+
+func TestParseEncounterOutcome(t *testing.T) {
+	for name, expected := range map[string]EncounterOutcome{"": EncounterNone, "none": EncounterNone,
+		"fight": EncounterFight, "Duel": EncounterDuel} {
+		outcome, err := ParseEncounterOutcome(name)
+		require.NoError(t, err)
+		require.Equal(t, expected, outcome)
+	}
+
+	_, err := ParseEncounterOutcome("hug")
+	require.ErrorIs(t, err, ErrInvalidRoadEncounter)
+
+	testWorld, _ := createTestWorld(2)
+	_, err = NewSimulation(testWorld, 1, 1, WithRoadEncounter(RoadEncounter{DestroyRoad: 2}))
+	require.ErrorIs(t, err, ErrInvalidRoadEncounter)
+}
@@ -1,15 +1,166 @@
 package types
 
-// Aliens map alien-id to city
-type Aliens map[int]*City
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrUnknownAlienClass = errors.New("unknown alien class")
+
+// AlienStatus describes the state of an alien
+type AlienStatus int
+
+const (
+	Alive AlienStatus = iota
+	Trapped
+	Dead
+)
+
+// String implements the stringer interface
+func (s AlienStatus) String() string {
+	switch s {
+	case Alive:
+		return "alive"
+	case Trapped:
+		return "trapped"
+	case Dead:
+		return "dead"
+	}
+
+	return "unknown"
+}
+
+// AlienClass gives an alien its abilities
+type AlienClass int
+
+const (
+	Regular AlienClass = iota
+	// Scout moves two hops per iteration
+	Scout
+	// Tank survives its first fight
+	Tank
+	// Bomber destroys the city it enters, even alone, and dies doing so
+	Bomber
+	// Stealth does not fight the other stealth aliens
+	Stealth
+)
+
+// AlienClasses lists every alien class
+var AlienClasses = []AlienClass{Regular, Scout, Tank, Bomber, Stealth}
+
+// String implements the stringer interface
+func (c AlienClass) String() string {
+	switch c {
+	case Regular:
+		return "regular"
+	case Scout:
+		return "scout"
+	case Tank:
+		return "tank"
+	case Bomber:
+		return "bomber"
+	case Stealth:
+		return "stealth"
+	}
+
+	return "unknown"
+}
+
+// ParseAlienClass returns the class with the given name
+func ParseAlienClass(name string) (AlienClass, error) {
+	for _, class := range AlienClasses {
+		if strings.EqualFold(name, class.String()) {
+			return class, nil
+		}
+	}
+
+	return Regular, fmt.Errorf("%w: %s", ErrUnknownAlienClass, name)
+}
+
+// Alien maintains the identity of an alien and the path it has travelled.
+// An alien is either in a City or, on multi-tick roads, in Transit.
+type Alien struct {
+	ID      int
+	Name    string
+	Faction string
+	Spawn   *City
+	City    *City
+	Transit *Transit
+	Path    []string
+	Moves   int
+	Status  AlienStatus
+	Class   AlienClass
+	// Armor is the number of fights the alien survives, given to the tanks
+	Armor int
+	// Energy is only used by the simulations with an energy model
+	Energy int
+}
+
+func NewAlien(id int, name string, city *City) *Alien {
+	return &Alien{
+		ID:     id,
+		Name:   name,
+		Spawn:  city,
+		City:   city,
+		Path:   []string{city.Name},
+		Status: Alive,
+	}
+}
+
+// MoveTo moves the alien to the given city and records it in the path
+func (a *Alien) MoveTo(city *City) {
+	a.City = city
+	a.Transit = nil
+	a.Path = append(a.Path, city.Name)
+	a.Moves++
+	a.Status = Alive
+}
+
+// Depart puts the alien on the road leading to the city
+func (a *Alien) Depart(direction Direction, city *City, ticks int) {
+	a.Transit = &Transit{From: a.City, To: city, Direction: direction, Remaining: ticks}
+	a.City = nil
+}
+
+// OnRoad checks whether the alien is travelling between two cities
+func (a *Alien) OnRoad() bool {
+	return a.Transit != nil
+}
+
+// Position returns a human readable position of the alien
+func (a *Alien) Position() string {
+	if a.Transit != nil {
+		return fmt.Sprintf("on the road from %s to %s", a.Transit.From.Name, a.Transit.To.Name)
+	}
+
+	if a.City != nil {
+		return a.City.Name
+	}
+
+	return "nowhere"
+}
+
+// String implements the stringer interface
+func (a *Alien) String() string {
+	if a.Name != "" {
+		return a.Name
+	}
+
+	return fmt.Sprintf("alien %d", a.ID)
+}
+
+// Aliens map alien-id to alien
+type Aliens map[int]*Alien
 
 // AddAlien creates a new entry in the map
-func (a Aliens) AddAlien(id int, city *City) {
-	a[id] = city
+func (a Aliens) AddAlien(alien *Alien) {
+	a[alien.ID] = alien
 }
 
-// GetAlien returns the city associated with the given alien Id
-func (a Aliens) GetAlien(id int) *City {
+// GetAlien returns the alien associated with the given alien Id
+func (a Aliens) GetAlien(id int) *Alien {
 	return a[id]
 }
 
@@ -17,3 +168,15 @@ func (a Aliens) GetAlien(id int) *City {
 func (a Aliens) DeleteAlien(id int) {
 	delete(a, id)
 }
+
+// IDs returns the alien Ids in ascending order
+func (a Aliens) IDs() []int {
+	ids := make([]int, 0, len(a))
+	for id := range a {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	return ids
+}
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AttributeKind is the type of a city attribute value
+type AttributeKind int
+
+const (
+	StringAttribute AttributeKind = iota
+	IntAttribute
+	FloatAttribute
+	BoolAttribute
+)
+
+// The attributes used by the simulation, both are non negative integers
+const (
+	AttributeDefense    = "defense"
+	AttributePopulation = "population"
+)
+
+// Attribute is a typed value attached to a city, the type is inferred from the raw value
+type Attribute struct {
+	Kind  AttributeKind
+	Raw   string
+	Int   int
+	Float float64
+	Bool  bool
+}
+
+// ParseAttribute infers the type of the raw value, falling back to a string
+func ParseAttribute(raw string) Attribute {
+	if number, err := strconv.Atoi(raw); err == nil {
+		return Attribute{Kind: IntAttribute, Raw: raw, Int: number, Float: float64(number)}
+	}
+
+	if number, err := strconv.ParseFloat(raw, 64); err == nil {
+		return Attribute{Kind: FloatAttribute, Raw: raw, Float: number}
+	}
+
+	switch strings.ToLower(raw) {
+	case "true":
+		return Attribute{Kind: BoolAttribute, Raw: raw, Bool: true}
+	case "false":
+		return Attribute{Kind: BoolAttribute, Raw: raw, Bool: false}
+	}
+
+	return Attribute{Kind: StringAttribute, Raw: raw}
+}
+
+// String implements the stringer interface
+func (a Attribute) String() string {
+	return a.Raw
+}
+
+// SetAttribute stores the attribute, the value type is inferred
+func (c *City) SetAttribute(key, value string) {
+	if c.Attributes == nil {
+		c.Attributes = make(map[string]Attribute)
+	}
+
+	c.Attributes[key] = ParseAttribute(value)
+}
+
+// setIntAttribute stores the integer attribute
+func (c *City) setIntAttribute(key string, value int) {
+	if c.Attributes == nil {
+		c.Attributes = make(map[string]Attribute)
+	}
+
+	c.Attributes[key] = Attribute{Kind: IntAttribute, Raw: strconv.Itoa(value), Int: value, Float: float64(value)}
+}
+
+// Defense returns the defense level of the city, zero when it has none
+func (c *City) Defense() int {
+	defense, _ := c.IntAttribute(AttributeDefense)
+
+	return defense
+}
+
+// SetDefense sets the defense level of the city
+func (c *City) SetDefense(defense int) {
+	c.setIntAttribute(AttributeDefense, defense)
+}
+
+// Population returns the population of the city, zero when it has none
+func (c *City) Population() int {
+	population, _ := c.IntAttribute(AttributePopulation)
+
+	return population
+}
+
+// SetPopulation sets the population of the city
+func (c *City) SetPopulation(population int) {
+	c.setIntAttribute(AttributePopulation, population)
+}
+
+// Attribute returns the attribute associated with the key
+func (c *City) Attribute(key string) (Attribute, bool) {
+	attribute, ok := c.Attributes[key]
+
+	return attribute, ok
+}
+
+// IntAttribute returns the attribute value if it is an integer
+func (c *City) IntAttribute(key string) (int, bool) {
+	attribute, ok := c.Attributes[key]
+	if !ok || attribute.Kind != IntAttribute {
+		return 0, false
+	}
+
+	return attribute.Int, true
+}
+
+// FloatAttribute returns the attribute value if it is a number
+func (c *City) FloatAttribute(key string) (float64, bool) {
+	attribute, ok := c.Attributes[key]
+	if !ok || (attribute.Kind != IntAttribute && attribute.Kind != FloatAttribute) {
+		return 0, false
+	}
+
+	return attribute.Float, true
+}
+
+// BoolAttribute returns the attribute value if it is a boolean
+func (c *City) BoolAttribute(key string) (bool, bool) {
+	attribute, ok := c.Attributes[key]
+	if !ok || attribute.Kind != BoolAttribute {
+		return false, false
+	}
+
+	return attribute.Bool, true
+}
+
+// StringAttribute returns the raw attribute value, whatever its type
+func (c *City) StringAttribute(key string) (string, bool) {
+	attribute, ok := c.Attributes[key]
+
+	return attribute.Raw, ok
+}
+
+// AttributeKeys returns the attribute keys in alphabetical order
+func (c *City) AttributeKeys() []string {
+	keys := make([]string, 0, len(c.Attributes))
+	for key := range c.Attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
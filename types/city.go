@@ -4,111 +4,170 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strconv"
 	"strings"
-)
+	"unicode"
 
-var (
-	ErrInvalidDirection = errors.New("invalid direction")
-	ErrNoNeighbours     = errors.New("no neighbour")
+	"golang.org/x/text/unicode/norm"
 )
 
-//Direction in an integer representation of a real world direction
-type Direction int
+var ErrNoNeighbours = errors.New("no neighbour")
+
+// CityState describes the lifecycle of a city
+type CityState int
 
 const (
-	North Direction = iota
-	South
-	East
-	West
+	Intact CityState = iota
+	// Ruins are destroyed cities retained for a later rebuild
+	Ruins
 )
 
+// String implements the stringer interface
+func (s CityState) String() string {
+	switch s {
+	case Intact:
+		return "intact"
+	case Ruins:
+		return "ruins"
+	}
+
+	return "unknown"
+}
+
 // City maintains the links to the neighbouring cities and alien occupancy
 type City struct {
-	Name           string
-	Neighbours     map[Direction]*City
-	OccupiedAliens map[int]interface{}
+	Name       string
+	Neighbours map[Direction]*City
+	Roads      map[Direction]Road
+	// Inbound indexes the roads of the other cities leading into this one
+	Inbound        map[RoadRef]bool
+	OccupiedAliens map[int]*Alien
+	State          CityState
+	Damaged        bool
+	// Attributes holds the typed city attributes, including the defense and the population
+	Attributes map[string]Attribute
 }
 
 func NewCity(name string, neighboursCount int) *City {
 	return &City{
-		Name:       name,
+		Name:       NormalizeName(name),
 		Neighbours: make(map[Direction]*City, neighboursCount),
 	}
 }
 
-// GetNeighbours returns a non nil random neighbour
-func (c *City) PickRandomNeighbours() (*City, error) {
-	validNeigbours := make([]*City, 0)
+// PickRandomNeighbours returns a non nil random neighbour drawn from the given random source,
+// the chance of each neighbour is proportional to the weight of the road leading to it.
+func (c *City) PickRandomNeighbours(rng *rand.Rand) (*City, error) {
+	direction, err := c.PickRandomDirection(rng)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Neighbours[direction], nil
+}
 
-	for _, city := range c.Neighbours {
-		if city != nil {
-			validNeigbours = append(validNeigbours, city)
+// PickRandomDirection returns the direction of a non nil random neighbour, weighted as in PickRandomNeighbours
+func (c *City) PickRandomDirection(rng *rand.Rand) (Direction, error) {
+	validNeigbours := make([]Direction, 0)
+	totalWeight := 0
+
+	for _, link := range c.Links() {
+		if !link.Road.Blocked {
+			validNeigbours = append(validNeigbours, link.Direction)
+			totalWeight += c.RoadWeight(link.Direction)
 		}
 	}
 
-	if len(validNeigbours) > 0 {
-		return validNeigbours[rand.Intn(len(validNeigbours))], nil
+	if len(validNeigbours) == 0 {
+		return 0, ErrNoNeighbours
 	}
 
-	return nil, ErrNoNeighbours
+	target := rng.Intn(totalWeight)
+	for _, direction := range validNeigbours {
+		if target -= c.RoadWeight(direction); target < 0 {
+			return direction, nil
+		}
+	}
+
+	return validNeigbours[len(validNeigbours)-1], nil
 }
 
 // AddAlien adds the alien to the city
-func (c *City) AddAlien(alien int) {
+func (c *City) AddAlien(alien *Alien) {
 	if c.OccupiedAliens == nil {
-		c.OccupiedAliens = make(map[int]interface{})
+		c.OccupiedAliens = make(map[int]*Alien)
 	}
 
-	c.OccupiedAliens[alien] = nil
+	c.OccupiedAliens[alien.ID] = alien
+}
+
+// RemoveAlien removes the alien from the city
+func (c *City) RemoveAlien(id int) {
+	delete(c.OccupiedAliens, id)
 }
 
 // AddNeighbour adds the given city as neighbour if the direction is valid
 func (c *City) AddNeighbour(direction string, city *City) error {
-	if city.Neighbours == nil {
-		city.Neighbours = make(map[Direction]*City)
+	dir, err := ParseDirection(direction)
+	if err != nil {
+		return err
 	}
 
-	switch strings.ToLower(direction) {
-	case "north":
-		c.Neighbours[North] = city
-	case "south":
-		c.Neighbours[South] = city
-	case "east":
-		c.Neighbours[East] = city
-	case "west":
-		c.Neighbours[West] = city
-	default:
-		return ErrInvalidDirection
-	}
+	c.SetNeighbour(dir, city)
 
 	return nil
 }
 
-// GetDirection returns the string representation of the given direction
-func GetDirection(direction Direction) string {
-	switch direction {
-	case North:
-		return "north"
-	case South:
-		return "south"
-	case East:
-		return "east"
-	case West:
-		return "west"
+// String implements the stringer interface
+func (c *City) String() string {
+	neighbours := ""
+
+	for _, link := range c.Links() {
+		neighbours += fmt.Sprintf("%s=%s", GetDirection(link.Direction), QuoteName(link.To.Name))
+		if length := c.RoadLength(link.Direction); length != DefaultRoadLength {
+			neighbours += fmt.Sprintf(":%d:%d", c.RoadWeight(link.Direction), length)
+		} else if weight := c.RoadWeight(link.Direction); weight != DefaultRoadWeight {
+			neighbours += fmt.Sprintf(":%d", weight)
+		}
+
+		neighbours += " "
+	}
+
+	if defense := c.Defense(); defense > 0 {
+		neighbours += fmt.Sprintf("defense=%d ", defense)
+	}
+
+	if population := c.Population(); population > 0 {
+		neighbours += fmt.Sprintf("population=%d ", population)
+	}
+
+	for _, key := range c.AttributeKeys() {
+		if key == AttributeDefense || key == AttributePopulation {
+			continue
+		}
+
+		neighbours += fmt.Sprintf("@%s=%s ", key, QuoteName(c.Attributes[key].String()))
 	}
 
-	return "invalid direction"
+	return fmt.Sprintf("%s %s", QuoteName(c.Name), neighbours)
 }
 
-// String implements the stringer interface
-func (c *City) String() string {
-	neighbours := ""
+// NormalizeName returns the Unicode NFC form of the name, used for every city lookup
+func NormalizeName(name string) string {
+	return norm.NFC.String(name)
+}
+
+// QuoteName returns the name quoted when it cannot be written as a bare map token
+func QuoteName(name string) string {
+	if name == "" || strings.HasPrefix(name, "@") {
+		return strconv.Quote(name)
+	}
 
-	for direction, city := range c.Neighbours {
-		if city != nil {
-			neighbours += fmt.Sprintf("%s=%s ", GetDirection(direction), city.Name)
+	for _, r := range name {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) || strings.ContainsRune(`"=:#\`, r) {
+			return strconv.Quote(name)
 		}
 	}
 
-	return fmt.Sprintf("%s %s", c.Name, neighbours)
+	return name
 }
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidDirection    = errors.New("invalid direction")
+	ErrUnknownDirectionSet = errors.New("unknown direction set")
+)
+
+// Direction in an integer representation of a real world direction
+type Direction int
+
+const (
+	North Direction = iota
+	South
+	East
+	West
+	NorthEast
+	NorthWest
+	SouthEast
+	SouthWest
+	Up
+	Down
+)
+
+// Directions lists all the supported directions, in the order they are written
+var Directions = []Direction{North, South, East, West, NorthEast, NorthWest, SouthEast, SouthWest, Up, Down}
+
+var directionNames = map[Direction]string{
+	North:     "north",
+	South:     "south",
+	East:      "east",
+	West:      "west",
+	NorthEast: "northeast",
+	NorthWest: "northwest",
+	SouthEast: "southeast",
+	SouthWest: "southwest",
+	Up:        "up",
+	Down:      "down",
+}
+
+// DirectionSet is a named set of directions, every direction has an opposite within the set
+type DirectionSet struct {
+	Name       string
+	Directions []Direction
+	Opposites  map[Direction]Direction
+}
+
+var (
+	// FourWay is the default compass
+	FourWay = newDirectionSet("4way", [][2]Direction{{North, South}, {East, West}})
+	// EightWay adds the diagonal directions to the compass
+	EightWay = newDirectionSet("8way", [][2]Direction{
+		{North, South}, {East, West}, {NorthEast, SouthWest}, {NorthWest, SouthEast},
+	})
+	// Hex links the cells of a hexagonal grid with flat sides to the north and south
+	Hex = newDirectionSet("hex", [][2]Direction{{North, South}, {NorthEast, SouthWest}, {NorthWest, SouthEast}})
+	// ThreeD adds vertical links to the compass
+	ThreeD = newDirectionSet("3d", [][2]Direction{{North, South}, {East, West}, {Up, Down}})
+)
+
+// DirectionSets lists the registered direction sets
+var DirectionSets = []DirectionSet{FourWay, EightWay, Hex, ThreeD}
+
+func newDirectionSet(name string, pairs [][2]Direction) DirectionSet {
+	set := DirectionSet{Name: name, Opposites: make(map[Direction]Direction, 2*len(pairs))}
+
+	for _, pair := range pairs {
+		set.Opposites[pair[0]] = pair[1]
+		set.Opposites[pair[1]] = pair[0]
+	}
+	// Keep the written order of the directions
+	for _, direction := range Directions {
+		if _, ok := set.Opposites[direction]; ok {
+			set.Directions = append(set.Directions, direction)
+		}
+	}
+
+	return set
+}
+
+// GetDirectionSet returns the direction set registered under the given name
+func GetDirectionSet(name string) (DirectionSet, error) {
+	for _, set := range DirectionSets {
+		if strings.EqualFold(set.Name, name) {
+			return set, nil
+		}
+	}
+
+	return DirectionSet{}, ErrUnknownDirectionSet
+}
+
+// Contains checks whether the direction belongs to the set
+func (d DirectionSet) Contains(direction Direction) bool {
+	_, ok := d.Opposites[direction]
+
+	return ok
+}
+
+// Opposite returns the opposite of the direction within the set
+func (d DirectionSet) Opposite(direction Direction) (Direction, bool) {
+	opposite, ok := d.Opposites[direction]
+
+	return opposite, ok
+}
+
+// ParseDirection returns the direction with the given name
+func ParseDirection(name string) (Direction, error) {
+	name = strings.ToLower(name)
+
+	for direction, directionName := range directionNames {
+		if directionName == name {
+			return direction, nil
+		}
+	}
+
+	return 0, ErrInvalidDirection
+}
+
+// GetDirection returns the string representation of the given direction
+func GetDirection(direction Direction) string {
+	if name, ok := directionNames[direction]; ok {
+		return name
+	}
+
+	return "invalid direction"
+}
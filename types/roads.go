@@ -0,0 +1,164 @@
+package types
+
+import "sort"
+
+const (
+	// DefaultRoadWeight is the weight of the roads without an explicit one
+	DefaultRoadWeight = 1
+	// DefaultRoadLength is the length of the roads without an explicit one, they are traversed in a single tick
+	DefaultRoadLength = 1
+)
+
+// Road holds the properties of the road leading to a neighbour
+type Road struct {
+	// Weight is the relative chance of the road being taken, e.g. highways versus back roads
+	Weight int
+	// Length is the number of ticks needed to traverse the road
+	Length int
+	// Blocked roads cannot be taken until they are reopened
+	Blocked bool
+}
+
+// RoadRef identifies the road leaving a city in the given direction
+type RoadRef struct {
+	From      *City
+	Direction Direction
+}
+
+// Link is a road leaving a city along with the neighbour it leads to
+type Link struct {
+	Direction Direction
+	To        *City
+	Road      Road
+}
+
+// Links returns the roads leaving the city in the order of the directions
+func (c *City) Links() []Link {
+	links := make([]Link, 0, len(c.Neighbours))
+
+	for _, direction := range Directions {
+		if neighbour := c.Neighbours[direction]; neighbour != nil {
+			links = append(links, Link{Direction: direction, To: neighbour, Road: c.Roads[direction]})
+		}
+	}
+
+	return links
+}
+
+// RoadWeight returns the weight of the road in the given direction
+func (c *City) RoadWeight(direction Direction) int {
+	if road, ok := c.Roads[direction]; ok && road.Weight > 0 {
+		return road.Weight
+	}
+
+	return DefaultRoadWeight
+}
+
+// SetRoadWeight sets the weight of the road in the given direction
+func (c *City) SetRoadWeight(direction Direction, weight int) {
+	if c.Roads == nil {
+		c.Roads = make(map[Direction]Road)
+	}
+
+	road := c.Roads[direction]
+	road.Weight = weight
+	c.Roads[direction] = road
+}
+
+// RoadLength returns the length of the road in the given direction
+func (c *City) RoadLength(direction Direction) int {
+	if road, ok := c.Roads[direction]; ok && road.Length > 0 {
+		return road.Length
+	}
+
+	return DefaultRoadLength
+}
+
+// SetRoadLength sets the length of the road in the given direction
+func (c *City) SetRoadLength(direction Direction, length int) {
+	if c.Roads == nil {
+		c.Roads = make(map[Direction]Road)
+	}
+
+	road := c.Roads[direction]
+	road.Length = length
+	c.Roads[direction] = road
+}
+
+// RoadBlocked checks whether the road in the given direction is blocked
+func (c *City) RoadBlocked(direction Direction) bool {
+	return c.Roads[direction].Blocked
+}
+
+// SetRoadBlocked blocks or reopens the road in the given direction
+func (c *City) SetRoadBlocked(direction Direction, blocked bool) {
+	if c.Roads == nil {
+		c.Roads = make(map[Direction]Road)
+	}
+
+	road := c.Roads[direction]
+	road.Blocked = blocked
+	c.Roads[direction] = road
+}
+
+// SetNeighbour links the city to the neighbour in the given direction, the
+// inbound roads of the neighbour are kept up to date.
+func (c *City) SetNeighbour(direction Direction, neighbour *City) {
+	c.RemoveNeighbour(direction)
+
+	if neighbour == nil {
+		return
+	}
+
+	if c.Neighbours == nil {
+		c.Neighbours = make(map[Direction]*City)
+	}
+
+	if neighbour.Inbound == nil {
+		neighbour.Inbound = make(map[RoadRef]bool)
+	}
+
+	c.Neighbours[direction] = neighbour
+	neighbour.Inbound[RoadRef{From: c, Direction: direction}] = true
+}
+
+// RemoveNeighbour removes the road in the given direction along with its
+// properties and returns the neighbour it led to.
+func (c *City) RemoveNeighbour(direction Direction) *City {
+	neighbour := c.Neighbours[direction]
+	if neighbour != nil {
+		delete(neighbour.Inbound, RoadRef{From: c, Direction: direction})
+	}
+
+	delete(c.Neighbours, direction)
+	delete(c.Roads, direction)
+
+	return neighbour
+}
+
+// InboundRoads returns the roads leading into the city, ordered by origin and direction
+func (c *City) InboundRoads() []RoadRef {
+	roads := make([]RoadRef, 0, len(c.Inbound))
+	for road := range c.Inbound {
+		roads = append(roads, road)
+	}
+
+	sort.Slice(roads, func(i, j int) bool {
+		if roads[i].From.Name != roads[j].From.Name {
+			return roads[i].From.Name < roads[j].From.Name
+		}
+
+		return roads[i].Direction < roads[j].Direction
+	})
+
+	return roads
+}
+
+// Transit describes an alien travelling on a road between two cities
+type Transit struct {
+	From      *City
+	To        *City
+	Direction Direction
+	// Remaining is the number of ticks left before the arrival
+	Remaining int
+}
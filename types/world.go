@@ -2,10 +2,13 @@ package types
 
 import (
 	"errors"
+	"sort"
 )
 
 var (
-	ErrCityExists = errors.New("city already exists")
+	ErrCityExists   = errors.New("city already exists")
+	ErrCityNotFound = errors.New("city not found")
+	ErrRoadNotFound = errors.New("road not found")
 )
 
 // World is mapping of city name to City instance
@@ -17,21 +20,95 @@ func NewWorldMap() World {
 
 // DeleteCity removes the city entry from the map
 func (w World) DeleteCity(city string) {
-	delete(w, city)
+	delete(w, NormalizeName(city))
 }
 
-// AddCity adds new city to the map
+// AddCity adds new city to the map, the city is keyed by the NFC form of its name
 func (w World) AddCity(city *City) error {
-	if _, ok := w[city.Name]; ok {
+	name := NormalizeName(city.Name)
+	if _, ok := w[name]; ok {
 		return ErrCityExists
 	}
 
-	w[city.Name] = city
+	w[name] = city
 
 	return nil
 }
 
-// GetCity returns the city associated with the city name
+// GetCity returns the city associated with the city name, whatever its Unicode normalisation form
 func (w World) GetCity(name string) *City {
-	return w[name]
+	return w[NormalizeName(name)]
+}
+
+// Names returns the city names in alphabetical order
+func (w World) Names() []string {
+	names := make([]string, 0, len(w))
+	for name := range w {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// AddRoad links the from city to the to city in the given direction, an existing road in that direction is replaced
+func (w World) AddRoad(from string, direction Direction, to string) error {
+	fromCity, toCity := w.GetCity(from), w.GetCity(to)
+	if fromCity == nil || toCity == nil {
+		return ErrCityNotFound
+	}
+
+	fromCity.SetNeighbour(direction, toCity)
+
+	return nil
+}
+
+// RemoveRoad removes the road leaving the city in the given direction and returns the city it led to
+func (w World) RemoveRoad(from string, direction Direction) (*City, error) {
+	city := w.GetCity(from)
+	if city == nil {
+		return nil, ErrCityNotFound
+	}
+
+	if city.Neighbours[direction] == nil {
+		return nil, ErrRoadNotFound
+	}
+
+	return city.RemoveNeighbour(direction), nil
+}
+
+// SetRoadBlocked blocks or reopens the road leaving the city in the given direction
+func (w World) SetRoadBlocked(from string, direction Direction, blocked bool) error {
+	city := w.GetCity(from)
+	if city == nil {
+		return ErrCityNotFound
+	}
+
+	if city.Neighbours[direction] == nil {
+		return ErrRoadNotFound
+	}
+
+	city.SetRoadBlocked(direction, blocked)
+
+	return nil
+}
+
+// RemoveCityRoads removes every road leading in or out of the city, the
+// inbound roads are found through the reverse index.
+func (w World) RemoveCityRoads(name string) error {
+	city := w.GetCity(name)
+	if city == nil {
+		return ErrCityNotFound
+	}
+
+	for _, road := range city.InboundRoads() {
+		road.From.RemoveNeighbour(road.Direction)
+	}
+
+	for _, direction := range Directions {
+		city.RemoveNeighbour(direction)
+	}
+
+	return nil
 }